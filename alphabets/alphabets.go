@@ -0,0 +1,197 @@
+package alphabets
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	Latin   = "latin"
+	Kryptos = "kryptos"
+	Latin25 = "latin25"
+	Digits  = "digits"
+)
+
+// Names lists the predefined alphabets
+var Names = []string{Latin, Kryptos, Latin25, Digits}
+
+// Alphabet is an ordered set of symbols (e.g., the letters A–Z)
+// in which ciphertexts are written. The zero value is the Latin alphabet.
+type Alphabet struct {
+	name    string
+	symbols []rune
+	indexes map[rune]int
+
+	// symbols merged into another one (e.g., J into I)
+	merged map[rune]rune
+}
+
+// Get returns a predefined alphabet:
+//   - latin: A–Z,
+//   - kryptos: the keyed alphabet of the Kryptos tableau (KRYPTOSABCDEFGHIJLMNQUVWXZ),
+//   - latin25: A–Z without J (merged into I),
+//   - digits: 0–9.
+func Get(name string) (Alphabet, error) {
+	switch name {
+	case Latin:
+		return GetLatin(), nil
+	case Kryptos:
+		return newAlphabet(Kryptos, "KRYPTOSABCDEFGHIJLMNQUVWXZ", nil)
+	case Latin25:
+		return newAlphabet(Latin25, "ABCDEFGHIKLMNOPQRSTUVWXYZ", map[rune]rune{'J': 'I'})
+	case Digits:
+		return newAlphabet(Digits, "0123456789", nil)
+	default:
+		return Alphabet{}, fmt.Errorf(
+			"unknown alphabet: %q (available: %s)",
+			name,
+			strings.Join(Names, ", "),
+		)
+	}
+}
+
+var latin, _ = newAlphabet(Latin, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", nil)
+
+// GetLatin returns the Latin alphabet (A–Z)
+func GetLatin() Alphabet {
+	return latin
+}
+
+// New returns an alphabet consisting of arbitrary symbols
+// (e.g., a Zodiac-style symbol set)
+func New(symbols string) (Alphabet, error) {
+	return newAlphabet(symbols, symbols, nil)
+}
+
+// Parse returns a predefined alphabet or, if name is not one of them,
+// the alphabet consisting of its symbols
+func Parse(name string) (Alphabet, error) {
+	for _, predefined := range Names {
+		if name == predefined {
+			return Get(name)
+		}
+	}
+
+	return New(name)
+}
+
+func newAlphabet(name, symbols string, merged map[rune]rune) (Alphabet, error) {
+	alphabet := Alphabet{
+		name:    name,
+		symbols: []rune(symbols),
+		indexes: make(map[rune]int),
+		merged:  merged,
+	}
+
+	if len(alphabet.symbols) < 2 {
+		return Alphabet{}, fmt.Errorf("alphabet %q: at least 2 symbols are required", name)
+	}
+
+	for i, symbol := range alphabet.symbols {
+		if unicode.IsSpace(symbol) {
+			return Alphabet{}, fmt.Errorf("alphabet %q: whitespace cannot be a symbol", name)
+		}
+
+		if _, ok := alphabet.indexes[symbol]; ok {
+			return Alphabet{}, fmt.Errorf("alphabet %q: duplicate symbol %q", name, symbol)
+		}
+
+		alphabet.indexes[symbol] = i
+	}
+
+	return alphabet, nil
+}
+
+// orDefault returns the Latin alphabet in place of the zero value
+func (a Alphabet) orDefault() Alphabet {
+	if a.symbols == nil {
+		return latin
+	}
+	return a
+}
+
+func (a Alphabet) Name() string {
+	return a.orDefault().name
+}
+
+// Symbols returns the symbols of the alphabet, in order
+func (a Alphabet) Symbols() []rune {
+	return append([]rune(nil), a.orDefault().symbols...)
+}
+
+// Len returns the number of symbols of the alphabet
+func (a Alphabet) Len() int {
+	return len(a.orDefault().symbols)
+}
+
+// Symbol returns the symbol at a given index
+func (a Alphabet) Symbol(i int) rune {
+	return a.orDefault().symbols[i]
+}
+
+// Index returns the index of a symbol in the alphabet
+// (-1 if the symbol does not belong to the alphabet)
+func (a Alphabet) Index(symbol rune) int {
+	if i, ok := a.orDefault().indexes[symbol]; ok {
+		return i
+	}
+	return -1
+}
+
+func (a Alphabet) Contains(symbol rune) bool {
+	return a.Index(symbol) >= 0
+}
+
+// Normalize returns the symbol of the alphabet corresponding to a character:
+// the character itself, its uppercase, or the symbol it is merged into
+// (e.g., 'j' is 'I' in the latin25 alphabet)
+func (a Alphabet) Normalize(c rune) (rune, bool) {
+	a = a.orDefault()
+
+	for _, candidate := range []rune{c, unicode.ToUpper(c)} {
+		if merged, ok := a.merged[candidate]; ok {
+			candidate = merged
+		}
+
+		if a.Contains(candidate) {
+			return candidate, true
+		}
+	}
+
+	return c, false
+}
+
+// Validate ensures that a ciphertext only consists of symbols of the alphabet
+func (a Alphabet) Validate(ciphertext string) error {
+	position := 0
+	for _, c := range ciphertext {
+		if !a.Contains(c) {
+			return fmt.Errorf(
+				"invalid character %q at position %d: not in the %s alphabet",
+				c,
+				position,
+				a.Name(),
+			)
+		}
+		position++
+	}
+
+	return nil
+}
+
+// Shift returns the symbol located n symbols after a given symbol,
+// wrapping around the end of the alphabet
+// (the symbol is returned unchanged if it does not belong to the alphabet)
+func (a Alphabet) Shift(symbol rune, n int) rune {
+	i := a.Index(symbol)
+	if i < 0 {
+		return symbol
+	}
+
+	return a.Symbol(((i+n)%a.Len() + a.Len()) % a.Len())
+}
+
+func (a Alphabet) String() string {
+	return string(a.orDefault().symbols)
+}
@@ -0,0 +1,128 @@
+package alphabets
+
+import (
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	type test struct {
+		name           string
+		expectedLength int
+		expectedFirst  rune
+	}
+
+	tests := []test{
+		{name: Latin, expectedLength: 26, expectedFirst: 'A'},
+		{name: Kryptos, expectedLength: 26, expectedFirst: 'K'},
+		{name: Latin25, expectedLength: 25, expectedFirst: 'A'},
+		{name: Digits, expectedLength: 10, expectedFirst: '0'},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			alphabet, err := Get(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if alphabet.Len() != tc.expectedLength {
+				t.Errorf("length — expected: %d, got: %d", tc.expectedLength, alphabet.Len())
+			}
+
+			if alphabet.Symbol(0) != tc.expectedFirst {
+				t.Errorf("first symbol — expected: %c, got: %c", tc.expectedFirst, alphabet.Symbol(0))
+			}
+		})
+	}
+
+	if _, err := Get("cyrillic"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var alphabet Alphabet
+
+	if alphabet.Name() != Latin || alphabet.String() != GetLatin().String() {
+		t.Errorf("expected the Latin alphabet, got: %s", alphabet.Name())
+	}
+}
+
+func TestParse(t *testing.T) {
+	type test struct {
+		value         string
+		expectedName  string
+		expectedError bool
+	}
+
+	tests := []test{
+		{value: "kryptos", expectedName: Kryptos},
+		{value: "△○◇☐⊕", expectedName: "△○◇☐⊕"},
+		{value: "ABA", expectedError: true},
+		{value: "A", expectedError: true},
+		{value: "A B", expectedError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			alphabet, err := Parse(tc.value)
+			if tc.expectedError != (err != nil) {
+				t.Fatalf("error — expected: %t, got: %v", tc.expectedError, err)
+			}
+
+			if err == nil && alphabet.Name() != tc.expectedName {
+				t.Errorf("name — expected: %s, got: %s", tc.expectedName, alphabet.Name())
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	latin25, _ := Get(Latin25)
+	digits, _ := Get(Digits)
+
+	type test struct {
+		name           string
+		alphabet       Alphabet
+		input          rune
+		expectedSymbol rune
+		expectedValid  bool
+	}
+
+	tests := []test{
+		{name: "latin, uppercase", alphabet: GetLatin(), input: 'J', expectedSymbol: 'J', expectedValid: true},
+		{name: "latin, lowercase", alphabet: GetLatin(), input: 'j', expectedSymbol: 'J', expectedValid: true},
+		{name: "latin25, merged", alphabet: latin25, input: 'j', expectedSymbol: 'I', expectedValid: true},
+		{name: "digits, letter", alphabet: digits, input: 'A', expectedSymbol: 'A', expectedValid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			symbol, valid := tc.alphabet.Normalize(tc.input)
+			if symbol != tc.expectedSymbol || valid != tc.expectedValid {
+				t.Errorf("expected: %c (%t), got: %c (%t)",
+					tc.expectedSymbol,
+					tc.expectedValid,
+					symbol,
+					valid,
+				)
+			}
+		})
+	}
+}
+
+func TestShift(t *testing.T) {
+	kryptos, _ := Get(Kryptos)
+
+	if shifted := kryptos.Shift('Z', 1); shifted != 'K' {
+		t.Errorf("expected: K, got: %c", shifted)
+	}
+
+	if shifted := kryptos.Shift('K', -1); shifted != 'Z' {
+		t.Errorf("expected: Z, got: %c", shifted)
+	}
+
+	if shifted := GetLatin().Shift('A', 27); shifted != 'B' {
+		t.Errorf("expected: B, got: %c", shifted)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// runAnalyze analyzes K4, an arbitrary ciphertext, a batch of ciphertexts,
+// or the pseudo-K4 of a seeded simulation
+func runAnalyze(args []string) int {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(),
+			"Usage: %s analyze [options] [-]\n\n"+
+				"Analyze K4 (default), an arbitrary ciphertext, a batch of ciphertexts "+
+				"(one per line, optionally preceded by an id and a tab; '-': standard input), "+
+				"or the pseudo-K4 of a seeded simulation.\n\n",
+			os.Args[0],
+		)
+		flags.PrintDefaults()
+	}
+
+	analysis := addAnalysisFlags(flags)
+	generator := addGeneratorFlags(flags)
+	customCiphertext := flags.String(
+		"ciphertext",
+		"",
+		"custom analysis of an arbitrary ciphertext "+
+			"(whitespace, line breaks, and '?' characters are stripped)",
+	)
+	ciphertextPath := flags.String(
+		"ciphertext-file",
+		"",
+		"custom analysis of the ciphertext of a file "+
+			"(e.g., a transcription laid out as on the sculpture)",
+	)
+	lenient := flags.Bool(
+		"lenient",
+		false,
+		"drop the invalid characters of the ciphertexts instead of rejecting them",
+	)
+	originalPositions := flags.Bool(
+		"original-positions",
+		false,
+		"locate the segments in the original text of the ciphertexts as well "+
+			"(original_spans of the json and ndjson formats)",
+	)
+	inputPath := flags.String(
+		"input",
+		"",
+		"file of ciphertexts to analyze, one per line, optionally preceded by an id "+
+			"and a tab (e.g., \"K1<tab>EMUFP HZLRF\"; -: standard input)",
+	)
+	replay := flags.Uint(
+		"replay",
+		0,
+		"regenerate and analyze the pseudo-K4 of a given simulation id (requires --seed)",
+	)
+	signature := flags.String(
+		"signature",
+		"",
+		"print all the groups, whatever their separators and partitions, "+
+			"with a given letter frequency distribution shape (e.g., 5-4-4-3)",
+	)
+	sharedSignatures := flags.Bool(
+		"shared-signatures",
+		false,
+		"print the letter frequency distribution shapes of groups "+
+			"produced by several sets of separators",
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	setFlags := getSetFlags(flags)
+
+	switch {
+	case flags.NArg() == 1 && flags.Arg(0) == "-" && *inputPath == "":
+		*inputPath = "-"
+	case flags.NArg() > 0:
+		flags.Usage()
+		return exitUsage
+	}
+
+	sourcesCount := 0
+	for _, set := range []bool{
+		*inputPath != "",
+		*customCiphertext != "",
+		*ciphertextPath != "",
+		*replay != 0,
+	} {
+		if set {
+			sourcesCount++
+		}
+	}
+
+	if sourcesCount > 1 {
+		fmt.Fprintln(os.Stderr,
+			"--input, --ciphertext, --ciphertext-file, and --replay are mutually exclusive",
+		)
+		return exitUsage
+	}
+
+	if *replay != 0 && !setFlags["seed"] {
+		fmt.Fprintln(os.Stderr, "--replay requires --seed")
+		return exitUsage
+	}
+
+	// a pseudo-K4 has no original text
+	if *replay != 0 && *originalPositions {
+		fmt.Fprintln(os.Stderr, "--original-positions does not apply to --replay")
+		return exitUsage
+	}
+
+	alphabet, err := analysis.getAlphabet()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	separatorSets, err := getSeparatorSets(
+		*analysis.separators,
+		*analysis.combinations,
+		alphabet,
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	var simulationId uint
+
+	normalizeOptions := helpers.NormalizeOptions{
+		Lenient:       *lenient,
+		KeepPositions: *originalPositions,
+		Alphabet:      alphabet,
+	}
+
+	if *ciphertextPath != "" {
+		content, err := os.ReadFile(*ciphertextPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		*customCiphertext = string(content)
+	}
+
+	if *customCiphertext == "" {
+		*customCiphertext = k4
+	}
+
+	// K4 is written in the alphabet as well
+	// (e.g., J becomes I in the latin25 alphabet)
+	normalized, err := helpers.Normalize(*customCiphertext, normalizeOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	reportDropped(normalized.Dropped)
+	ciphertext := normalized.Ciphertext
+
+	// regenerate the pseudo-K4 of a seeded simulation
+	if *replay != 0 {
+		nullModel, err := generator.getNullModel(alphabet)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitUsage
+		}
+
+		simulationId = *replay
+		ciphertext = nullModel.Generate(
+			getRandomSource(generator.seed, simulationId),
+			len(k4),
+		)
+	}
+
+	p, err := newPipeline(
+		analysis,
+		alphabet,
+		separatorSets,
+		helpers.GetStatisticsRecorder(),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+	p.seed = generator.seed
+
+	// near-matches are ranked by similarity
+	// once the analysis is completed
+	p.rank = *analysis.minSimilarity < 1
+
+	// the groups are indexed by signature
+	// to be queried once the analysis is completed
+	if *signature != "" || *sharedSignatures {
+		p.query = &signaturesQuery{shared: *sharedSignatures}
+
+		if *signature != "" {
+			parsed, err := frequencies.ParseSignature(*signature)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				return exitUsage
+			}
+			p.query.signature = &parsed
+		}
+	}
+
+	if *inputPath != "" {
+		return runBatch(p, *inputPath, separatorSets, normalizeOptions)
+	}
+
+	return p.run(func(ctx context.Context, jobs chan<- analyzer.Job) {
+		analysisJobs := analyzer.GetJobs(ciphertext, simulationId, separatorSets)
+		for i := range analysisJobs {
+			analysisJobs[i].Positions = normalized.Positions
+		}
+
+		sendJobs(ctx, jobs, analysisJobs)
+	})
+}
+
+// runBatch analyzes the ciphertexts of an input file
+// (-: standard input)
+func runBatch(
+	p *pipeline,
+	path string,
+	separatorSets [][]rune,
+	normalizeOptions helpers.NormalizeOptions,
+) int {
+	r := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		defer file.Close()
+		r = file
+	}
+
+	// invalid inputs are reported and skipped
+	failed := false
+
+	exitCode := p.run(func(ctx context.Context, jobs chan<- analyzer.Job) {
+		reader := analyzer.NewInputReader(r, normalizeOptions)
+
+		for {
+			input, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				failed = true
+
+				if errors.Is(err, analyzer.ErrInvalidInput) {
+					continue
+				}
+				return
+			}
+
+			reportDropped(input.Dropped)
+
+			if !sendJobs(ctx, jobs, analyzer.GetInputJobs(input, separatorSets)) {
+				return
+			}
+		}
+	})
+
+	if exitCode == exitSuccess && failed {
+		return exitFailure
+	}
+
+	return exitCode
+}
+
+// reportDropped reports the invalid characters dropped in lenient mode
+func reportDropped(dropped []helpers.InvalidCharacter) {
+	for _, invalid := range dropped {
+		fmt.Fprintf(os.Stderr, "dropped %q at %s\n", invalid.Character, invalid.Position)
+	}
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import "sync/atomic"
+
+// Activity counts the jobs processed by the workers
+// (e.g., to monitor a simulation). It is safe for concurrent use.
+type Activity struct {
+	workers   atomic.Int64
+	busy      atomic.Int64
+	processed atomic.Uint64
+}
+
+func NewActivity() *Activity {
+	return &Activity{}
+}
+
+// the methods below ignore a nil activity
+
+func (a *Activity) setWorkers(workers int) {
+	if a != nil {
+		a.workers.Store(int64(workers))
+	}
+}
+
+func (a *Activity) start() {
+	if a != nil {
+		a.busy.Add(1)
+	}
+}
+
+func (a *Activity) done() {
+	if a != nil {
+		a.busy.Add(-1)
+		a.processed.Add(1)
+	}
+}
+
+// Workers returns the number of workers
+func (a *Activity) Workers() int {
+	return int(a.workers.Load())
+}
+
+// BusyWorkers returns the number of workers processing a job
+func (a *Activity) BusyWorkers() int {
+	return int(a.busy.Load())
+}
+
+// ProcessedJobs returns the number of jobs processed
+func (a *Activity) ProcessedJobs() uint64 {
+	return a.processed.Load()
+}
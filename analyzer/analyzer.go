@@ -0,0 +1,455 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/groups"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+const defaultWorkersCount = 20
+
+// MaxSimilaritySegments is the maximum number of segments of a job whose
+// groups of different lengths are compared (Bell numbers: 115975 partitions
+// of 10 segments, about 5·10^10 of 20): above it, only the groups with
+// identical shapes are searched
+const MaxSimilaritySegments = 10
+
+// DefaultKnownCollections is the default maximum number of collections
+// kept in memory to skip the ones already processed
+const DefaultKnownCollections = 1_000_000
+
+var ErrEmptyCiphertext = errors.New("empty ciphertext")
+
+type Options struct {
+	// number of workers to process the analysis in parallel
+	// (default: 20)
+	Workers int
+
+	// sets of symbols acting as separators at once
+	// (default: each symbol of the alphabet on its own)
+	SeparatorSets [][]rune
+
+	// alphabet of the ciphertexts (default: A–Z)
+	Alphabet alphabets.Alphabet
+
+	// minimum similarity between the letter frequency distribution shapes
+	// of the groups, up to 1 (default: 1, i.e., identical shapes and groups
+	// of the same length). The zero value leaves the option unset (the
+	// default applies), so that a minimum similarity of 0 cannot be
+	// requested: the CLI and serve require a value greater than 0 and up
+	// to 1. Below 1, groups of different lengths are compared, unless the
+	// segments of a job are too many (see MaxSimilaritySegments).
+	MinSimilarity float64
+
+	// index of the groups produced by the jobs, by letter frequency
+	// distribution shape, if any (every partition is indexed, not only
+	// the matching collections)
+	Index *Index
+
+	// activity of the workers, if monitored
+	Activity *Activity
+
+	// generator of the collections shared by the workers, keeping track
+	// of the collections already processed by each job (default: a generator
+	// keeping at most DefaultKnownCollections collections in memory)
+	Generator *groups.GroupsGenerator
+
+	// send, after the results of each job, a result marking its completion
+	// (e.g., to know when all the jobs of a pseudo-K4 have been analyzed;
+	// a job interrupted by the cancellation of the analysis is not completed)
+	NotifyCompletions bool
+}
+
+// Job is the analysis of a ciphertext split based on a set of separators
+type Job struct {
+	Ciphertext   string
+	Separators   []rune
+	SimulationId uint
+
+	// identifier of the ciphertext in a batch of inputs, if any
+	InputId string
+
+	// position in the original text of each symbol of the ciphertext,
+	// if kept (see helpers.NormalizeOptions.KeepPositions)
+	Positions []helpers.Position
+}
+
+// scope returns the scope of the collections of the job
+// (the collections of a job are only compared to one another)
+func (j Job) scope() string {
+	return fmt.Sprintf("%d\t%s\t%s\t%s",
+		j.SimulationId,
+		j.InputId,
+		string(j.Separators),
+		j.Ciphertext,
+	)
+}
+
+// Result is a collection of groups with identical letters frequency
+// distribution shapes
+type Result struct {
+	Job
+	Collection     *groups.Collection
+	Classification helpers.Classification
+
+	// similarity between the letter frequency distribution shapes
+	// of the groups (1 for identical shapes)
+	Similarity float64
+
+	// whether the groups have identical letter frequency distribution
+	// shapes (and therefore the same length)
+	IdenticalShapes bool
+
+	// the result only marks the completion of its job: all its results
+	// have been sent before (no collection; see Options.NotifyCompletions)
+	JobCompleted bool
+
+	// the result only reports that the groups of different lengths of its
+	// job have not been compared, its segments being too many (no collection;
+	// see MaxSimilaritySegments): only identical shapes have been searched
+	TooManySegments bool
+}
+
+// Record returns the structured representation of the result
+func (r Result) Record() helpers.Record {
+	record := helpers.NewRecord(
+		r.Ciphertext,
+		r.Separators,
+		r.SimulationId,
+		r.Collection.Groups,
+		r.Classification,
+	)
+	record.Similarity = r.Similarity
+	record.InputId = r.InputId
+	record.SetOriginalSpans(r.Positions)
+
+	return record
+}
+
+func (o Options) separatorSets() [][]rune {
+	if len(o.SeparatorSets) == 0 {
+		return GetSeparatorSets(o.Alphabet, 1)
+	}
+	return o.SeparatorSets
+}
+
+// similarityMode identifies whether groups of different lengths are
+// compared or not (0 leaves the minimum similarity unset: identical shapes)
+func (o Options) similarityMode() bool {
+	return o.MinSimilarity > 0 && o.MinSimilarity < 1
+}
+
+func (o Options) workersCount() int {
+	if o.Workers <= 0 {
+		return defaultWorkersCount
+	}
+	return o.Workers
+}
+
+// Validate ensures that a ciphertext written in an alphabet can be analyzed
+func Validate(ciphertext string, alphabet alphabets.Alphabet) error {
+	if ciphertext == "" {
+		return ErrEmptyCiphertext
+	}
+
+	return alphabet.Validate(ciphertext)
+}
+
+// GetSeparatorSets returns all the sets of size symbols of an alphabet
+// (example: A–Z and size 2: "AB", "AC", ..., "YZ")
+func GetSeparatorSets(alphabet alphabets.Alphabet, size int) [][]rune {
+	return helpers.Combinations(alphabet.Symbols(), size)
+}
+
+// GetJobs returns the jobs analyzing a ciphertext with each set of separators
+func GetJobs(ciphertext string, simulationId uint, separatorSets [][]rune) []Job {
+	var jobs []Job
+
+	for _, separators := range separatorSets {
+		jobs = append(jobs, Job{
+			Ciphertext:   ciphertext,
+			Separators:   separators,
+			SimulationId: simulationId,
+		})
+	}
+
+	return jobs
+}
+
+// hasAdjacentSeparators identifies whether two separators
+// are contiguous in the ciphertext or not
+func hasAdjacentSeparators(ciphertext string, separators []rune) bool {
+	previousIsSeparator := false
+
+	for _, c := range ciphertext {
+		isSeparator := slices.Contains(separators, c)
+		if isSeparator && previousIsSeparator {
+			return true
+		}
+		previousIsSeparator = isSeparator
+	}
+
+	return false
+}
+
+// getValidCollections returns collections of groups with
+// identical letters frequency distribution shapes
+// (none once the analysis is canceled)
+func getValidCollections(
+	ctx context.Context,
+	generator *groups.GroupsGenerator,
+	segments []groups.Segment,
+	job Job,
+	index *Index,
+) []*groups.Collection {
+	var validCollections []*groups.Collection
+
+	// the partitions are filtered as soon as they are found
+	// (only the valid ones are kept in memory)
+	generator.VisitSegmentPartitions(segments, func(collection *groups.Collection) {
+		index.AddCollection(job, collection)
+
+		if frequencies.HaveIdenticalShapes(collection) {
+			validCollections = append(validCollections, collection)
+		}
+	})
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return validCollections
+}
+
+// getSimilarCollections returns collections of groups, whatever their
+// lengths, with similar letters frequency distribution shapes
+// (none once the analysis is canceled)
+func getSimilarCollections(
+	ctx context.Context,
+	generator *groups.GroupsGenerator,
+	segments []groups.Segment,
+	minSimilarity float64,
+	job Job,
+	index *Index,
+) []*groups.Collection {
+	var similarCollections []*groups.Collection
+
+	// the partitions are filtered as soon as they are found
+	// (there are too many of them to be kept in memory)
+	generator.VisitAllSegmentPartitions(segments, func(collection *groups.Collection) {
+		index.AddCollection(job, collection)
+
+		if frequencies.CollectionSimilarity(collection) >= minSimilarity {
+			similarCollections = append(similarCollections, collection)
+		}
+	})
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return similarCollections
+}
+
+// runAnalysis sends the collections of groups with identical letters
+// frequency distribution shapes found by a job and returns false
+// if the analysis has been canceled before the job was completed
+func runAnalysis(ctx context.Context, job Job, options Options, results chan<- Result) bool {
+	// the analysis has been canceled while the job was queued
+	if ctx.Err() != nil {
+		return false
+	}
+
+	// separators should be immediately surrounded by nonseparators
+	// (e.g., a ciphertext containing a doublet separator 'XX' should be
+	// excluded, as well as 'WX' if both 'W' and 'X' are separators)
+	if hasAdjacentSeparators(job.Ciphertext, job.Separators) {
+		return true
+	}
+
+	// partition the segments split based on the separators
+	// into groups of the same length
+	// example: "AAXBBXCCXDD" and separator 'X':
+	// "AA", "BB" | "CC", "DD"; "AA", "CC" | "BB", "DD"; etc.
+	// (the segments carry their location in the ciphertext, and the
+	// enumeration stops as soon as the analysis is canceled)
+	generator := options.Generator.WithContext(ctx).WithScope(job.scope())
+	segments := helpers.SplitSegments(job.Ciphertext, job.Separators...)
+
+	// groups of different lengths are only compared if the number
+	// of partitions of the segments remains tractable
+	similarityMode := options.similarityMode()
+	if similarityMode && len(segments) > MaxSimilaritySegments {
+		similarityMode = false
+
+		select {
+		case results <- Result{Job: job, TooManySegments: true}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// analyze the collections to identify groups with
+	// the same (or similar) letters frequency shapes
+	var collections []*groups.Collection
+	if similarityMode {
+		collections = getSimilarCollections(
+			ctx,
+			generator,
+			segments,
+			options.MinSimilarity,
+			job,
+			options.Index,
+		)
+	} else {
+		collections = getValidCollections(ctx, generator, segments, job, options.Index)
+	}
+
+	// the enumeration has been interrupted
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for _, collection := range collections {
+		result := Result{
+			Job:            job,
+			Collection:     collection,
+			Classification: helpers.Classify(job.Ciphertext, collection.Groups),
+			Similarity:     frequencies.CollectionSimilarity(collection),
+		}
+
+		result.IdenticalShapes = frequencies.HaveIdenticalShapes(collection)
+
+		// the letter frequencies are reported along with the groups
+		frequencies.ComputeLetterFrequencies(collection)
+
+		select {
+		case results <- result:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// Run processes jobs in parallel and streams the results. The results
+// channel is closed once the jobs channel is closed and all the jobs
+// have been processed, or once the context is canceled.
+func Run(ctx context.Context, jobs <-chan Job, options Options) <-chan Result {
+	var wg sync.WaitGroup
+
+	results := make(chan Result, options.workersCount())
+
+	if options.Generator == nil {
+		options.Generator = groups.GetGroupsGeneratorWithLimit(DefaultKnownCollections)
+	}
+	options.Activity.setWorkers(options.workersCount())
+
+	// start workers
+	for w := 1; w <= options.workersCount(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case job, ok := <-jobs:
+					if !ok {
+						return
+					}
+					options.Activity.start()
+					completed := runAnalysis(ctx, job, options, results)
+					options.Activity.done()
+
+					if !completed || !options.NotifyCompletions {
+						continue
+					}
+
+					select {
+					case results <- Result{Job: job, JobCompleted: true}:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Stream analyzes a ciphertext with all the sets of separators
+// and streams the results
+func Stream(ctx context.Context, ciphertext string, options Options) (<-chan Result, error) {
+	if err := Validate(ciphertext, options.Alphabet); err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan Job)
+	go func() {
+		defer close(jobs)
+
+		for _, job := range GetJobs(ciphertext, 0, options.separatorSets()) {
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return Run(ctx, jobs, options), nil
+}
+
+// Analyze analyzes a ciphertext with all the sets of separators and returns
+// the collections of groups with identical (or similar) letters frequency
+// distribution shapes, ranked by similarity then ordered by separators
+// (the results only reporting too many segments are left out)
+func Analyze(ctx context.Context, ciphertext string, options Options) ([]Result, error) {
+	stream, err := Stream(ctx, ciphertext, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for result := range stream {
+		if result.TooManySegments {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	Rank(results)
+
+	return results, nil
+}
+
+// Rank sorts results by descending similarity, then by separators
+func Rank(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return slices.Compare(results[i].Separators, results[j].Separators) < 0
+	})
+}
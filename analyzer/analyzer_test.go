@@ -0,0 +1,298 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+const k4 = "OBKR" +
+	"UOXOGHULBSOLIFBBWFLRVQQPRNGKSSO" +
+	"TWTQSJQSSEKZZWATJKLUDIAWINFBNYP" +
+	"VTTMZFPKWGDKZXTJCDIGKUHUAUEKCAR"
+
+func TestAnalyze(t *testing.T) {
+	results, err := Analyze(context.Background(), k4, Options{Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("results — expected: 1, got: %d", len(results))
+	}
+
+	result := results[0]
+	if string(result.Separators) != "W" {
+		t.Errorf("separator — expected: W, got: %s", string(result.Separators))
+	}
+
+	if len(result.Collection.Groups) != 2 {
+		t.Errorf("groups — expected: 2, got: %d", len(result.Collection.Groups))
+	}
+
+	if !result.Classification.K4Like {
+		t.Error("expected K4-like groups")
+	}
+
+	// the segments carry their location in K4
+	for _, group := range result.Collection.Groups {
+		if !group.HasSpans() {
+			t.Fatalf("expected located segments: %+v", group)
+		}
+
+		for i, span := range group.Spans {
+			if segment := k4[span.Start:span.End]; segment != group.Segments[i] {
+				t.Errorf("location — expected: %s, got: %s", group.Segments[i], segment)
+			}
+		}
+	}
+}
+
+func TestRunSharedGenerator(t *testing.T) {
+	generator := groups.GetGroupsGeneratorWithLimit(100)
+
+	jobs := make(chan Job, 2)
+	for simulationId := uint(1); simulationId <= 2; simulationId++ {
+		jobs <- Job{Ciphertext: k4, Separators: []rune("W"), SimulationId: simulationId}
+	}
+	close(jobs)
+
+	// the collections of a job are not compared to the ones of another job
+	var results []Result
+	for result := range Run(context.Background(), jobs, Options{Generator: generator}) {
+		results = append(results, result)
+	}
+
+	if len(results) != 2 {
+		t.Errorf("results — expected: 2, got: %d", len(results))
+	}
+
+	if generator.GetDuplicatesCount() != 0 {
+		t.Errorf("duplicates — expected: 0, got: %d", generator.GetDuplicatesCount())
+	}
+}
+
+func TestAnalyzeTooManySegments(t *testing.T) {
+	// 12 segments: only identical shapes are searched
+	ciphertext := "AAXBBXCCXDDXEEXFFXGGXHHXIIXJJXKKXLL"
+	options := Options{MinSimilarity: 0.9, SeparatorSets: [][]rune{[]rune("X")}}
+
+	jobs := make(chan Job, 1)
+	jobs <- Job{Ciphertext: ciphertext, Separators: []rune("X")}
+	close(jobs)
+
+	tooManySegments := 0
+	for result := range Run(context.Background(), jobs, options) {
+		switch {
+		case result.TooManySegments:
+			tooManySegments++
+		case !result.IdenticalShapes:
+			t.Errorf("expected identical shapes: %+v", result.Collection.Groups)
+		}
+	}
+
+	if tooManySegments != 1 {
+		t.Errorf("too many segments — expected: 1, got: %d", tooManySegments)
+	}
+
+	// the results reporting too many segments are left out
+	results, err := Analyze(context.Background(), ciphertext, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		if result.TooManySegments || result.Collection == nil {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+}
+
+func TestAnalyzeInvalidCiphertext(t *testing.T) {
+	for _, ciphertext := range []string{"", "ABC DEF", "abc"} {
+		if _, err := Analyze(context.Background(), ciphertext, Options{}); err == nil {
+			t.Errorf("%q — expected an error", ciphertext)
+		}
+	}
+}
+
+func TestAnalyzeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Analyze(ctx, k4, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestAnalyzeSeparatorSets(t *testing.T) {
+	// 'X' and 'Y' both act as separators
+	results, err := Analyze(
+		context.Background(),
+		"AABXCCDYBBAXDDC",
+		Options{SeparatorSets: [][]rune{{'X', 'Y'}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// AAB CCD | BBA DDC; AAB DDC | BBA CCD;
+	// AAB BBA | CCD DDC; AAB | CCD | BBA | DDC
+	if len(results) != 4 {
+		t.Fatalf("results — expected: 4, got: %d", len(results))
+	}
+
+	for _, result := range results {
+		if string(result.Separators) != "XY" {
+			t.Errorf("separators — expected: XY, got: %s", string(result.Separators))
+		}
+	}
+
+	// adjacent separators are excluded
+	results, err = Analyze(
+		context.Background(),
+		"AABXYCCDBBAXDDC",
+		Options{SeparatorSets: [][]rune{{'X', 'Y'}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 0 {
+		t.Errorf("results — expected: 0, got: %d", len(results))
+	}
+}
+
+func TestGetSeparatorSets(t *testing.T) {
+	for size, expected := range map[int]int{1: 26, 2: 325, 3: 2600} {
+		if sets := GetSeparatorSets(alphabets.GetLatin(), size); len(sets) != expected {
+			t.Errorf("size %d — expected: %d, got: %d", size, expected, len(sets))
+		}
+	}
+}
+
+func TestAnalyzeMinSimilarity(t *testing.T) {
+	results, err := Analyze(context.Background(), k4, Options{MinSimilarity: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) < 2 {
+		t.Fatalf("results — expected: > 1, got: %d", len(results))
+	}
+
+	// the identical shapes rank first
+	if string(results[0].Separators) != "W" || !results[0].IdenticalShapes {
+		t.Errorf("expected the identical shapes (W) first, got: %s",
+			string(results[0].Separators),
+		)
+	}
+
+	for i, result := range results {
+		if result.Similarity < 0.9 {
+			t.Errorf("similarity — expected: >= 0.9, got: %f", result.Similarity)
+		}
+
+		if i > 0 && result.Similarity > results[i-1].Similarity {
+			t.Error("results are not ranked by similarity")
+		}
+	}
+}
+
+func TestAnalyzeAlphabet(t *testing.T) {
+	kryptos, _ := alphabets.Get(alphabets.Kryptos)
+
+	results, err := Analyze(context.Background(), k4, Options{Alphabet: kryptos})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || string(results[0].Separators) != "W" {
+		t.Errorf("expected the W separator only, got: %d results", len(results))
+	}
+
+	// "AABXCCDXBBAXDDC" written with symbols
+	symbols, _ := alphabets.New("△○◇☐X")
+
+	results, err = Analyze(context.Background(), "△△○X◇◇☐X○○△X☐☐◇", Options{Alphabet: symbols})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("results — expected: 4, got: %d", len(results))
+	}
+
+	for _, result := range results {
+		if string(result.Separators) != "X" {
+			t.Errorf("separator — expected: X, got: %s", string(result.Separators))
+		}
+	}
+
+	if _, err := Analyze(context.Background(), k4, Options{Alphabet: symbols}); err == nil {
+		t.Error("expected an error for a ciphertext written in another alphabet")
+	}
+}
+
+func TestAnalyzeActivity(t *testing.T) {
+	activity := NewActivity()
+
+	if _, err := Analyze(
+		context.Background(),
+		k4,
+		Options{Workers: 4, Activity: activity},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// one job per letter
+	if activity.ProcessedJobs() != 26 {
+		t.Errorf("processed jobs — expected: 26, got: %d", activity.ProcessedJobs())
+	}
+
+	if activity.Workers() != 4 || activity.BusyWorkers() != 0 {
+		t.Errorf("workers — expected: 0/4, got: %d/%d",
+			activity.BusyWorkers(),
+			activity.Workers(),
+		)
+	}
+}
+
+func TestRunNotifyCompletions(t *testing.T) {
+	jobs := make(chan Job)
+	go func() {
+		defer close(jobs)
+		for _, job := range GetJobs(k4, 1, GetSeparatorSets(alphabets.GetLatin(), 1)) {
+			jobs <- job
+		}
+	}()
+
+	completed := make(map[rune]bool)
+	for result := range Run(context.Background(), jobs, Options{
+		Workers:           4,
+		NotifyCompletions: true,
+	}) {
+		separator := result.Separators[0]
+
+		if !result.JobCompleted {
+			// the results of a job are sent before its completion
+			if completed[separator] {
+				t.Errorf("%c: result sent after the completion of its job", separator)
+			}
+			continue
+		}
+
+		if result.Collection != nil || completed[separator] {
+			t.Errorf("%c: unexpected completion", separator)
+		}
+		completed[separator] = true
+	}
+
+	// one job per letter, including the ones excluded by adjacent separators
+	if len(completed) != 26 {
+		t.Errorf("completed jobs — expected: 26, got: %d", len(completed))
+	}
+}
@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+// IndexEntry is a group of segments produced by a job
+type IndexEntry struct {
+	Job
+	Group     groups.Group
+	Signature frequencies.Signature
+}
+
+// SharedSignature is a letter frequency distribution shape produced
+// by several sets of separators
+type SharedSignature struct {
+	Signature  frequencies.Signature
+	Separators [][]rune
+	Entries    []IndexEntry
+}
+
+// Index maps the letter frequency distribution shapes to the groups
+// producing them, across all the separators, partitions, and ciphertexts
+// analyzed (e.g., to identify coincidences between separators).
+// It is safe for concurrent use.
+type Index struct {
+	mu      sync.Mutex
+	entries map[frequencies.Signature][]IndexEntry
+
+	// groups already indexed
+	known map[string]bool
+}
+
+func NewIndex() *Index {
+	return &Index{
+		entries: make(map[frequencies.Signature][]IndexEntry),
+		known:   make(map[string]bool),
+	}
+}
+
+// entryKey identifies a group produced by a job
+// (because the same group belongs to several collections)
+// (identical segments located at different places are distinct)
+func entryKey(job Job, group groups.Group) string {
+	segments := make([]string, len(group.Segments))
+	for i, segment := range group.Segments {
+		segments[i] = segment
+		if group.HasSpans() {
+			segments[i] += "@" + strconv.Itoa(group.Spans[i].Start)
+		}
+	}
+	sort.Strings(segments)
+
+	return strings.Join([]string{
+		job.InputId,
+		job.Ciphertext,
+		strconv.FormatUint(uint64(job.SimulationId), 10),
+		string(job.Separators),
+		strings.Join(segments, "."),
+	}, "/")
+}
+
+// AddCollection indexes the groups of a collection produced by a job
+// (a nil index ignores them)
+func (i *Index) AddCollection(job Job, collection *groups.Collection) {
+	if i == nil {
+		return
+	}
+
+	for _, group := range collection.Groups {
+		i.Add(job, group)
+	}
+}
+
+// Add indexes a group produced by a job, unless it is already indexed
+func (i *Index) Add(job Job, group groups.Group) {
+	signature := frequencies.ShapeSignature(group)
+	key := entryKey(job, group)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.known[key] {
+		return
+	}
+	i.known[key] = true
+
+	i.entries[signature] = append(i.entries[signature], IndexEntry{
+		Job: job,
+		Group: groups.Group{
+			Segments: slices.Clone(group.Segments),
+			Spans:    slices.Clone(group.Spans),
+		},
+		Signature: signature,
+	})
+}
+
+// Len returns the number of groups indexed
+func (i *Index) Len() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return len(i.known)
+}
+
+// Signatures returns the signatures indexed, from the most to
+// the least frequent
+func (i *Index) Signatures() []frequencies.Signature {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	signatures := make([]frequencies.Signature, 0, len(i.entries))
+	for signature := range i.entries {
+		signatures = append(signatures, signature)
+	}
+
+	sort.Slice(signatures, func(a, b int) bool {
+		countA, countB := len(i.entries[signatures[a]]), len(i.entries[signatures[b]])
+		if countA != countB {
+			return countA > countB
+		}
+		return signatures[a] < signatures[b]
+	})
+
+	return signatures
+}
+
+// Lookup returns the groups having a given signature,
+// ordered by input, separators, then segments
+func (i *Index) Lookup(signature frequencies.Signature) []IndexEntry {
+	i.mu.Lock()
+	entries := slices.Clone(i.entries[signature])
+	i.mu.Unlock()
+
+	sortEntries(entries)
+
+	return entries
+}
+
+// Shared returns the signatures produced by at least two sets
+// of separators, from the most to the least shared
+func (i *Index) Shared() []SharedSignature {
+	var shared []SharedSignature
+
+	for _, signature := range i.Signatures() {
+		entries := i.Lookup(signature)
+
+		var separatorSets [][]rune
+		for _, entry := range entries {
+			if !slices.ContainsFunc(separatorSets, func(separators []rune) bool {
+				return slices.Equal(separators, entry.Separators)
+			}) {
+				separatorSets = append(separatorSets, entry.Separators)
+			}
+		}
+
+		if len(separatorSets) < 2 {
+			continue
+		}
+
+		slices.SortFunc(separatorSets, slices.Compare[[]rune])
+
+		shared = append(shared, SharedSignature{
+			Signature:  signature,
+			Separators: separatorSets,
+			Entries:    entries,
+		})
+	}
+
+	sort.SliceStable(shared, func(a, b int) bool {
+		return len(shared[a].Separators) > len(shared[b].Separators)
+	})
+
+	return shared
+}
+
+func sortEntries(entries []IndexEntry) {
+	sort.Slice(entries, func(a, b int) bool {
+		x, y := entries[a], entries[b]
+
+		switch {
+		case x.InputId != y.InputId:
+			return x.InputId < y.InputId
+		case x.SimulationId != y.SimulationId:
+			return x.SimulationId < y.SimulationId
+		case x.Ciphertext != y.Ciphertext:
+			return x.Ciphertext < y.Ciphertext
+		case !slices.Equal(x.Separators, y.Separators):
+			return slices.Compare(x.Separators, y.Separators) < 0
+		default:
+			return slices.Compare(x.Group.Segments, y.Group.Segments) < 0
+		}
+	})
+}
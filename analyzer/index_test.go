@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestIndex(t *testing.T) {
+	index := NewIndex()
+
+	jobX := Job{Ciphertext: "AABXCCDXEEF", Separators: []rune("X")}
+	jobY := Job{Ciphertext: "AABYCCD", Separators: []rune("Y"), InputId: "2"}
+
+	index.Add(jobX, groups.Group{Segments: []string{"AAB", "EEF"}})
+	index.Add(jobX, groups.Group{Segments: []string{"CCD"}})
+	index.Add(jobY, groups.Group{Segments: []string{"CCD"}})
+
+	// the same group in another collection is indexed once
+	index.Add(jobX, groups.Group{Segments: []string{"EEF", "AAB"}})
+
+	if index.Len() != 3 {
+		t.Errorf("length — expected: 3, got: %d", index.Len())
+	}
+
+	type test struct {
+		signature       string
+		expectedEntries int
+	}
+
+	tests := []test{
+		{signature: "2-2-1-1", expectedEntries: 1},
+		{signature: "2-1", expectedEntries: 2},
+		{signature: "3", expectedEntries: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.signature, func(t *testing.T) {
+			entries := index.Lookup(frequencies.Signature(tc.signature))
+			if len(entries) != tc.expectedEntries {
+				t.Errorf("entries — expected: %d, got: %d", tc.expectedEntries, len(entries))
+			}
+		})
+	}
+
+	signatures := index.Signatures()
+	if len(signatures) != 2 || signatures[0] != "2-1" {
+		t.Errorf("unexpected signatures: %v", signatures)
+	}
+
+	// "2-1" is produced by both 'X' and 'Y'
+	shared := index.Shared()
+	if len(shared) != 1 {
+		t.Fatalf("shared signatures — expected: 1, got: %d", len(shared))
+	}
+
+	if shared[0].Signature != "2-1" ||
+		len(shared[0].Separators) != 2 ||
+		string(shared[0].Separators[0]) != "X" ||
+		string(shared[0].Separators[1]) != "Y" {
+		t.Errorf("unexpected shared signature: %+v", shared[0])
+	}
+}
+
+func TestAnalyzeIndex(t *testing.T) {
+	index := NewIndex()
+
+	results, err := Analyze(context.Background(), k4, Options{Workers: 4, Index: index})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every group is indexed, including the matching ones
+	signature := frequencies.ShapeSignature(results[0].Collection.Groups[0])
+
+	entries := index.Lookup(signature)
+	if len(entries) < 2 {
+		t.Fatalf("entries — expected at least 2, got: %d", len(entries))
+	}
+
+	for _, entry := range entries {
+		if entry.Signature != signature {
+			t.Errorf("signature — expected: %s, got: %s", signature, entry.Signature)
+		}
+	}
+
+	if len(index.Signatures()) < 2 {
+		t.Error("expected the signatures of the other separators")
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+var ErrInvalidInput = errors.New("invalid input")
+
+// Input is a ciphertext to analyze in a batch of inputs
+type Input struct {
+	// identifier of the ciphertext
+	// (default: its line number)
+	Id string
+
+	Ciphertext string
+
+	// invalid characters dropped in lenient mode
+	// (offsets are relative to the line)
+	Dropped []helpers.InvalidCharacter
+
+	// position in the line of each symbol of the ciphertext, if kept
+	// (see helpers.NormalizeOptions.KeepPositions)
+	Positions []helpers.Position
+}
+
+// InputReader reads inputs, one ciphertext per line, optionally
+// preceded by an identifier and a tab (e.g., "K1\tEMUFPHZLRFAXYUSDJKZLDKRNSHGNFIVJ").
+// Ciphertexts are normalized (e.g., "K1\tEMUFP HZLRF?" and "EMUFP HZLRF?"
+// are accepted), and empty lines and lines starting with '#' are ignored.
+type InputReader struct {
+	scanner    *bufio.Scanner
+	options    helpers.NormalizeOptions
+	lineNumber int
+}
+
+func NewInputReader(r io.Reader, options helpers.NormalizeOptions) *InputReader {
+	return &InputReader{
+		scanner: bufio.NewScanner(r),
+		options: options,
+	}
+}
+
+// Next returns the next input (io.EOF once all the inputs have been read).
+// An invalid input (ErrInvalidInput) does not prevent the next ones
+// from being read.
+func (r *InputReader) Next() (Input, error) {
+	for r.scanner.Scan() {
+		r.lineNumber++
+
+		line := r.scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		input := Input{Id: strconv.Itoa(r.lineNumber)}
+
+		// the id is separated from the ciphertext by a tab, since
+		// ciphertexts may contain spaces (e.g., "EMUFP HZLRF?")
+		// (blanked so that the columns of the ciphertext are preserved)
+		if id, ciphertext, ok := strings.Cut(line, "\t"); ok && strings.TrimSpace(id) != "" {
+			input.Id = strings.TrimSpace(id)
+			line = strings.Repeat(" ", utf8.RuneCountInString(id)+1) + ciphertext
+		}
+
+		normalized, err := helpers.Normalize(line, r.options)
+
+		var invalid helpers.InvalidCharacter
+		if errors.As(err, &invalid) {
+			invalid.Position.Line = r.lineNumber
+			return input, fmt.Errorf("%w: %w", ErrInvalidInput, invalid)
+		}
+
+		if err != nil {
+			return input, fmt.Errorf("%w (line %d): %w", ErrInvalidInput, r.lineNumber, err)
+		}
+
+		input.Ciphertext = normalized.Ciphertext
+		input.Dropped = normalized.Dropped
+		for i := range input.Dropped {
+			input.Dropped[i].Position.Line = r.lineNumber
+		}
+
+		input.Positions = normalized.Positions
+		for i := range input.Positions {
+			input.Positions[i].Line = r.lineNumber
+		}
+
+		return input, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Input{}, err
+	}
+
+	return Input{}, io.EOF
+}
+
+// GetInputJobs returns the jobs analyzing an input with each set of separators
+func GetInputJobs(input Input, separatorSets [][]rune) []Job {
+	jobs := GetJobs(input.Ciphertext, 0, separatorSets)
+	for i := range jobs {
+		jobs[i].InputId = input.Id
+		jobs[i].Positions = input.Positions
+	}
+
+	return jobs
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+func TestInputReader(t *testing.T) {
+	type test struct {
+		id         string
+		ciphertext string
+		err        bool
+	}
+
+	content := "# test vectors\n" +
+		"K1\tEMUFPHZLRFAXYUSDJKZLDKRNSHGNFIVJ\n" +
+		"\n" +
+		"  abcabc  \n" +
+		"ABC DEF GHI\n" +
+		"K3\tEND YAH?\n" +
+		"K3\tEND.YAH\n" +
+		"EMUFP HZLRF?\n" +
+		"\tOBKR\n"
+
+	tests := []test{
+		{id: "K1", ciphertext: "EMUFPHZLRFAXYUSDJKZLDKRNSHGNFIVJ"},
+		{id: "4", ciphertext: "ABCABC"},
+		{id: "5", ciphertext: "ABCDEFGHI"},
+		{id: "K3", ciphertext: "ENDYAH"},
+		{id: "K3", err: true},
+		{id: "8", ciphertext: "EMUFPHZLRF"},
+		{id: "9", ciphertext: "OBKR"},
+	}
+
+	reader := NewInputReader(strings.NewReader(content), helpers.NormalizeOptions{})
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d %s", i, tc.id), func(t *testing.T) {
+			input, err := reader.Next()
+			if tc.err != errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("error — expected: %t, got: %v", tc.err, err)
+			}
+
+			if input.Id != tc.id {
+				t.Errorf("id — expected: %s, got: %s", tc.id, input.Id)
+			}
+
+			if input.Ciphertext != tc.ciphertext {
+				t.Errorf("ciphertext — expected: %s, got: %s", tc.ciphertext, input.Ciphertext)
+			}
+		})
+	}
+
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected: %v, got: %v", io.EOF, err)
+	}
+}
+
+func TestGetInputJobs(t *testing.T) {
+	jobs := GetInputJobs(
+		Input{Id: "K4", Ciphertext: k4},
+		[][]rune{{'W'}, {'X', 'Y'}},
+	)
+
+	if len(jobs) != 2 {
+		t.Fatalf("jobs — expected: 2, got: %d", len(jobs))
+	}
+
+	for _, job := range jobs {
+		if job.InputId != "K4" || job.Ciphertext != k4 || job.SimulationId != 0 {
+			t.Errorf("unexpected job: %+v", job)
+		}
+	}
+}
+
+func TestInputReaderLenient(t *testing.T) {
+	reader := NewInputReader(
+		strings.NewReader("\n  K3\tEND.YAH\n"),
+		helpers.NormalizeOptions{Lenient: true},
+	)
+
+	input, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if input.Ciphertext != "ENDYAH" {
+		t.Errorf("ciphertext — expected: ENDYAH, got: %s", input.Ciphertext)
+	}
+
+	expected := helpers.Position{Offset: 8, Line: 2, Column: 9}
+	if len(input.Dropped) != 1 || input.Dropped[0].Position != expected {
+		t.Errorf("dropped — expected: '.' at %v, got: %v", expected, input.Dropped)
+	}
+}
+
+func TestInputReaderPositions(t *testing.T) {
+	reader := NewInputReader(
+		strings.NewReader("\nK3\tEND YAH\n"),
+		helpers.NormalizeOptions{KeepPositions: true},
+	)
+
+	input, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the id is blanked: the positions are those of the line
+	if len(input.Positions) != 6 {
+		t.Fatalf("positions — expected: 6, got: %d", len(input.Positions))
+	}
+
+	expected := helpers.Position{Offset: 7, Line: 2, Column: 8}
+	if input.Positions[3] != expected {
+		t.Errorf("position of Y — expected: %v, got: %v", expected, input.Positions[3])
+	}
+
+	for _, job := range GetInputJobs(input, [][]rune{{'D'}}) {
+		if len(job.Positions) != len(input.Positions) {
+			t.Errorf("expected the positions in the job, got: %v", job.Positions)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package charts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// symbols per row of the ciphertext, as on the sculpture
+const defaultRowLength = 31
+
+// maximum number of bar charts side by side
+const chartsPerRow = 3
+
+// maximum number of steps of the vertical axis of a bar chart
+const maxTicks = 10
+
+// layout (in pixels)
+const (
+	margin = 20
+
+	// ciphertext
+	cellWidth  = 24
+	cellHeight = 36
+	fontSize   = 30
+
+	// bar charts
+	barSpacing  = 20
+	barWidth    = 8
+	plotHeight  = 150
+	axisWidth   = 24
+	chartHeight = plotHeight + 60
+	chartGap    = 40
+
+	// legend
+	legendHeight = 30
+)
+
+// colors of the groups (in order), of the separators,
+// and of the symbols that belong to no group
+var (
+	groupColors = []string{
+		"#6a4ca8", "#3b78d8", "#2a9d8f", "#e9a23b",
+		"#d45087", "#8c564b", "#5c9e31", "#7f7f7f",
+	}
+	separatorColor  = "#cc0000"
+	unassignedColor = "#bbbbbb"
+	gridColor       = "#dddddd"
+	textColor       = "#555555"
+)
+
+// Options are the options of the rendering
+type Options struct {
+	// number of symbols per row of the ciphertext (default: 31, as on the
+	// sculpture); the first row is the shortest one, aligned to the right
+	RowLength int
+}
+
+func (o Options) rowLength() int {
+	if o.RowLength <= 0 {
+		return defaultRowLength
+	}
+	return o.RowLength
+}
+
+// bar is the count of a symbol in a group
+type bar struct {
+	symbol string
+	count  int
+}
+
+// sortedBars returns the letter frequency of a group, from the most to the
+// least frequent symbol (then in alphabetical order)
+func sortedBars(group helpers.GroupRecord) []bar {
+	bars := make([]bar, 0, len(group.LetterFrequency))
+	for symbol, count := range group.LetterFrequency {
+		bars = append(bars, bar{symbol: symbol, count: count})
+	}
+
+	slices.SortFunc(bars, func(a, b bar) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.symbol, b.symbol)
+	})
+
+	return bars
+}
+
+// groupColor returns the color of the i-th group
+func groupColor(i int) string {
+	return groupColors[i%len(groupColors)]
+}
+
+// symbolColors returns the color of each symbol of the ciphertext:
+// the color of its group, or the color of the separators
+// (the groups are located using the spans of their segments, if known)
+func symbolColors(record helpers.Record) []string {
+	symbols := []rune(record.Ciphertext)
+	colors := make([]string, len(symbols))
+
+	for i, symbol := range symbols {
+		colors[i] = unassignedColor
+		if strings.ContainsRune(record.Separators, symbol) {
+			colors[i] = separatorColor
+		}
+	}
+
+	for i, group := range record.Groups {
+		for _, span := range group.Spans {
+			for offset := max(span[0], 0); offset < min(span[1], len(symbols)); offset++ {
+				colors[offset] = groupColor(i)
+			}
+		}
+	}
+
+	return colors
+}
+
+// rows splits the ciphertext into rows of a given length,
+// the first row being the remainder
+// (e.g., K4: 4 symbols, then 3 rows of 31 symbols)
+func rows(length, rowLength int) [][2]int {
+	var bounds [][2]int
+
+	start := 0
+	if first := length % rowLength; first > 0 {
+		bounds = append(bounds, [2]int{0, first})
+		start = first
+	}
+
+	for ; start < length; start += rowLength {
+		bounds = append(bounds, [2]int{start, start + rowLength})
+	}
+
+	return bounds
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// title describes the collection: its separators, its shape,
+// and the alternation of its groups
+func title(record helpers.Record) string {
+	parts := []string{"Separators: " + record.Separators}
+
+	if len(record.Groups) > 0 {
+		parts = append(parts, "Shape: "+record.Groups[0].Signature)
+	}
+
+	if record.Similarity < 1 {
+		parts = append(parts, fmt.Sprintf("Similarity: %.4f", record.Similarity))
+	}
+
+	if record.Alternation.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("Alternation: %s (p = %.4f)",
+			record.Alternation.Pattern,
+			record.Alternation.Probability,
+		))
+	}
+
+	return strings.Join(parts, "   ")
+}
+
+// writeCiphertext draws the ciphertext, each symbol in the color
+// of its group
+func writeCiphertext(b *strings.Builder, record helpers.Record, y int, options Options) int {
+	symbols := []rune(record.Ciphertext)
+	colors := symbolColors(record)
+	rowLength := options.rowLength()
+
+	for _, row := range rows(len(symbols), rowLength) {
+		y += cellHeight
+
+		// the rows are aligned to the right
+		x := margin + (rowLength-(row[1]-row[0]))*cellWidth
+
+		fmt.Fprintf(b, `<text x="%d" y="%d" font-family="monospace" font-size="%d">`,
+			x, y, fontSize,
+		)
+		for i := row[0]; i < row[1]; i++ {
+			fmt.Fprintf(b, `<tspan x="%d" fill="%s">%s</tspan>`,
+				x+(i-row[0])*cellWidth,
+				colors[i],
+				escape(string(symbols[i])),
+			)
+		}
+		b.WriteString("</text>\n")
+	}
+
+	return y
+}
+
+// chartWidth returns the width of the bar chart of a group
+func chartWidth(bars []bar) int {
+	return axisWidth + max(len(bars), 1)*barSpacing
+}
+
+// tickStep returns the step of the vertical axis of a bar chart
+// (1, 2, or 5 times a power of 10) yielding at most maxTicks steps
+func tickStep(maxCount int) int {
+	for magnitude := 1; ; magnitude *= 10 {
+		for _, factor := range []int{1, 2, 5} {
+			if step := factor * magnitude; maxCount/step <= maxTicks {
+				return step
+			}
+		}
+	}
+}
+
+// writeChart draws the letter frequency of a group, sorted
+// from the most to the least frequent symbol
+func writeChart(b *strings.Builder, bars []bar, maxCount, x, y int, color string) {
+	width := chartWidth(bars)
+	unit := float64(plotHeight) / float64(max(maxCount, 1))
+	bottom := y + plotHeight
+
+	// grid and vertical axis
+	for count := 0; count <= maxCount; count += tickStep(maxCount) {
+		lineY := float64(bottom) - float64(count)*unit
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`+"\n",
+			x+axisWidth-4, lineY, x+width, lineY, gridColor,
+		)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" font-family="sans-serif" font-size="12" `+
+			`fill="%s" text-anchor="end">%d</text>`+"\n",
+			x+axisWidth-8, lineY+4, textColor, count,
+		)
+	}
+
+	for i, bar := range bars {
+		barX := x + axisWidth + i*barSpacing + (barSpacing-barWidth)/2
+		height := float64(bar.count) * unit
+
+		fmt.Fprintf(b, `<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s">`+
+			`<title>%s: %d</title></rect>`+"\n",
+			barX, float64(bottom)-height, barWidth, height, color,
+			escape(bar.symbol), bar.count,
+		)
+		fmt.Fprintf(b, `<text x="%d" y="%d" font-family="sans-serif" font-size="12" `+
+			`fill="%s" text-anchor="middle">%s</text>`+"\n",
+			barX+barWidth/2, bottom+18, textColor, escape(bar.symbol),
+		)
+	}
+}
+
+// writeLegend names the group drawn in a color, along with its
+// number of segments and symbols
+func writeLegend(b *strings.Builder, group helpers.GroupRecord, i, x, y int) {
+	length := 0
+	for _, segment := range group.Segments {
+		length += utf8.RuneCountInString(segment)
+	}
+
+	fmt.Fprintf(b, `<rect x="%d" y="%d" width="40" height="16" fill="%s"/>`+"\n",
+		x, y-13, groupColor(i),
+	)
+	fmt.Fprintf(b, `<text x="%d" y="%d" font-family="sans-serif" font-size="16" `+
+		`fill="%s">Group %d (%d segments, %d symbols)</text>`+"\n",
+		x+50, y, groupColor(i), i+1, len(group.Segments), length,
+	)
+}
+
+// RenderSVG draws a collection of groups as an SVG image: the ciphertext,
+// each symbol in the color of its group and the separators in red,
+// then the letter frequency of each group as side-by-side bar charts
+// sorted from the most to the least frequent symbol (as in K4_groups.png)
+func RenderSVG(w io.Writer, record helpers.Record, options Options) error {
+	var body strings.Builder
+
+	// title
+	y := margin + 16
+	fmt.Fprintf(&body, `<text x="%d" y="%d" font-family="sans-serif" font-size="16" fill="%s">%s</text>`+"\n",
+		margin, y, textColor, escape(title(record)),
+	)
+	width := margin + 9*utf8.RuneCountInString(title(record))
+
+	// ciphertext
+	y = writeCiphertext(&body, record, y, options) + margin
+	width = max(width, margin+options.rowLength()*cellWidth)
+
+	// bar charts, on the same scale
+	groupsBars := make([][]bar, len(record.Groups))
+	maxCount := 0
+	for i, group := range record.Groups {
+		groupsBars[i] = sortedBars(group)
+		for _, bar := range groupsBars[i] {
+			maxCount = max(maxCount, bar.count)
+		}
+	}
+
+	for start := 0; start < len(record.Groups); start += chartsPerRow {
+		end := min(start+chartsPerRow, len(record.Groups))
+
+		x := margin
+		for i := start; i < end; i++ {
+			writeChart(&body, groupsBars[i], maxCount, x, y+10, groupColor(i))
+			writeLegend(&body, record.Groups[i], i, x+axisWidth, y+chartHeight+legendHeight)
+			x += max(chartWidth(groupsBars[i]), 320) + chartGap
+		}
+
+		width = max(width, x-chartGap)
+		y += chartHeight + legendHeight + margin
+	}
+
+	width += margin
+	height := y + margin
+
+	_, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n"+
+			`<rect width="100%%" height="100%%" fill="white"/>`+"\n"+
+			"%s</svg>\n",
+		width, height, width, height, body.String(),
+	)
+
+	return err
+}
@@ -0,0 +1,170 @@
+package charts
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+const k4 = "OBKR" +
+	"UOXOGHULBSOLIFBBWFLRVQQPRNGKSSO" +
+	"TWTQSJQSSEKZZWATJKLUDIAWINFBNYP" +
+	"VTTMZFPKWGDKZXTJCDIGKUHUAUEKCAR"
+
+func TestRows(t *testing.T) {
+	type test struct {
+		name           string
+		length         int
+		rowLength      int
+		expectedOutput [][2]int
+	}
+
+	tests := []test{
+		{
+			name:           "K4, as on the sculpture",
+			length:         97,
+			rowLength:      31,
+			expectedOutput: [][2]int{{0, 4}, {4, 35}, {35, 66}, {66, 97}},
+		},
+		{
+			name:           "full rows",
+			length:         6,
+			rowLength:      3,
+			expectedOutput: [][2]int{{0, 3}, {3, 6}},
+		},
+		{
+			name:           "one short row",
+			length:         2,
+			rowLength:      31,
+			expectedOutput: [][2]int{{0, 2}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output := rows(tc.length, tc.rowLength)
+			if !reflect.DeepEqual(output, tc.expectedOutput) {
+				t.Errorf("expected: %v, got: %v", tc.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	results, err := analyzer.Analyze(context.Background(), k4, analyzer.Options{
+		SeparatorSets: [][]rune{[]rune("W")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("results — expected: 1, got: %d", len(results))
+	}
+
+	var output bytes.Buffer
+	if err := RenderSVG(&output, results[0].Record(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the image is well-formed
+	fills := make(map[string]int)
+	rects := 0
+
+	decoder := xml.NewDecoder(bytes.NewReader(output.Bytes()))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %s", err.Error())
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "fill" && element.Name.Local == "tspan" {
+				fills[attr.Value]++
+			}
+		}
+
+		if element.Name.Local == "rect" {
+			rects++
+		}
+	}
+
+	// each symbol of K4 is colored: 5 separators, 2 groups of 46 symbols
+	if fills[separatorColor] != 5 ||
+		fills[groupColor(0)] != 46 ||
+		fills[groupColor(1)] != 46 {
+		t.Errorf("unexpected colors of the ciphertext: %v", fills)
+	}
+
+	// background, one bar per symbol of each group, and the legend
+	bars := len(results[0].Record().Groups[0].LetterFrequency) +
+		len(results[0].Record().Groups[1].LetterFrequency)
+	if rects != 1+bars+2 {
+		t.Errorf("rectangles — expected: %d, got: %d", 1+bars+2, rects)
+	}
+
+	for _, expected := range []string{
+		"Separators: W",
+		"Alternation: strict",
+		"Group 1 (3 segments, 46 symbols)",
+		"Group 2 (3 segments, 46 symbols)",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected: %q", expected)
+		}
+	}
+}
+
+func TestSortedBars(t *testing.T) {
+	bars := sortedBars(helpers.GroupRecord{
+		LetterFrequency: map[string]int{"B": 2, "C": 1, "A": 2, "D": 3},
+	})
+
+	// most frequent symbol first, then in alphabetical order
+	expected := []bar{
+		{symbol: "D", count: 3},
+		{symbol: "A", count: 2},
+		{symbol: "B", count: 2},
+		{symbol: "C", count: 1},
+	}
+
+	if !reflect.DeepEqual(bars, expected) {
+		t.Errorf("expected: %v, got: %v", expected, bars)
+	}
+}
+
+func TestTickStep(t *testing.T) {
+	tests := map[int]int{0: 1, 1: 1, 10: 1, 11: 2, 21: 2, 22: 5, 51: 5, 55: 10, 999: 100}
+
+	for maxCount, expected := range tests {
+		if step := tickStep(maxCount); step != expected {
+			t.Errorf("%d — expected: %d, got: %d", maxCount, expected, step)
+		}
+	}
+}
+
+func TestWriteChartTicks(t *testing.T) {
+	var b strings.Builder
+	writeChart(&b, []bar{{symbol: "A", count: 500}}, 500, 0, 0, groupColor(0))
+
+	// 0, 50, ..., 500
+	if lines := strings.Count(b.String(), "<line"); lines != 11 {
+		t.Errorf("grid lines — expected: 11, got: %d", lines)
+	}
+}
@@ -1,60 +1,126 @@
 package frequencies
 
 import (
-	"reflect"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/glethuillier/K4nundrum/groups"
 )
 
-func computeLetterFrequency(group *groups.Group) {
+// Signature is the canonical letter frequency distribution shape of
+// a group: the frequencies of its letters in descending order, letters
+// abstracted away (e.g., "5-4-4-3-3-3-2"). Groups have identical shapes
+// if and only if they have the same signature, which can be used as
+// a map key to bucket groups by shape.
+type Signature string
+
+func (s Signature) String() string {
+	return string(s)
+}
+
+// ParseSignature parses the signature of a letter frequency distribution
+// shape (e.g., "5-4-4-3"), whatever the order of its frequencies
+func ParseSignature(s string) (Signature, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("empty signature")
+	}
+
+	var counts []int
+	for _, value := range strings.Split(s, "-") {
+		count, err := strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			return "", fmt.Errorf("invalid signature %q: frequencies must be positive integers", s)
+		}
+		counts = append(counts, count)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	return joinCounts(counts), nil
+}
+
+// joinCounts returns the signature of frequencies in descending order
+func joinCounts(counts []int) Signature {
+	values := make([]string, len(counts))
+	for i, v := range counts {
+		values[i] = strconv.Itoa(v)
+	}
+
+	return Signature(strings.Join(values, "-"))
+}
+
+// countLetters returns the letter frequency of segments
+func countLetters(segments []string) map[rune]int {
 	frequency := make(map[rune]int)
 
-	for _, segment := range group.Segments {
+	for _, segment := range segments {
 		for _, c := range segment {
-			if _, ok := frequency[c]; !ok {
-				frequency[c] = 1
-			} else {
-				frequency[c]++
-			}
+			frequency[c]++
 		}
 	}
 
-	group.LetterFrequency = frequency
+	return frequency
 }
 
-// HaveIdenticalShapes identifies whether groups in a given collection
-// have the same letter frequency distribution shapes or not
-func HaveIdenticalShapes(collection *groups.Collection) bool {
-	// first, compute the letter frequency for each group
-	for i := 0; i < len(collection.Groups); i++ {
-		computeLetterFrequency(&collection.Groups[i])
+// LetterFrequency returns the letter frequency of a group
+// without altering it
+func LetterFrequency(group groups.Group) map[rune]int {
+	if group.LetterFrequency != nil {
+		return group.LetterFrequency
 	}
 
-	// then compare the values, abstracting away the letters
-	freqValues := make([][]int, len(collection.Groups))
-	for i := range freqValues {
-		freqValues[i] = make([]int, 26)
+	return countLetters(group.Segments)
+}
+
+// ComputeLetterFrequencies stores the letter frequency of each group
+// of a collection (e.g., to print them)
+func ComputeLetterFrequencies(collection *groups.Collection) {
+	for i := range collection.Groups {
+		collection.Groups[i].LetterFrequency = countLetters(collection.Groups[i].Segments)
 	}
+}
 
-	for i, group := range collection.Groups {
-		for _, v := range group.LetterFrequency {
-			freqValues[i] = append(freqValues[i], v)
-		}
+// shapeCounts returns the frequencies of the letters of a group
+// in descending order
+func shapeCounts(group groups.Group) []int {
+	frequency := LetterFrequency(group)
+
+	counts := make([]int, 0, len(frequency))
+	for _, v := range frequency {
+		counts = append(counts, v)
 	}
 
-	// sort the values for comparison purposes
-	for _, v := range freqValues {
-		sort.Ints(v)
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	return counts
+}
+
+// ShapeSignature returns the letter frequency distribution shape
+// of a group (the group is not altered)
+func ShapeSignature(group groups.Group) Signature {
+	return joinCounts(shapeCounts(group))
+}
+
+// HaveIdenticalShapes identifies whether groups in a given collection
+// have the same letter frequency distribution shapes or not
+// (the groups are not altered)
+func HaveIdenticalShapes(collection *groups.Collection) bool {
+	if len(collection.Groups) == 0 {
+		return true
 	}
 
-	for i := 0; i < len(freqValues)-1; i += 1 {
-		if !reflect.DeepEqual(freqValues[i], freqValues[i+1]) {
+	// compare the signatures, abstracting away the letters
+	signature := ShapeSignature(collection.Groups[0])
+	for _, group := range collection.Groups[1:] {
+		if ShapeSignature(group) != signature {
 			return false
 		}
 	}
 
-	// if the values match, the letter frequencies have
+	// if the signatures match, the letter frequencies have
 	// the same distribution shapes
 	return true
 }
@@ -103,20 +103,125 @@ func TestComputeLetterFrequency(t *testing.T) {
 		},
 	}
 
-	computeLetterFrequency(group)
+	frequency := LetterFrequency(*group)
 
 	for c := 'A'; c < 'Z'; c++ {
-		if group.LetterFrequency[c] != 1 {
+		if frequency[c] != 1 {
 			t.Errorf("%s — expected: 1, got: %d",
 				string(c),
-				group.LetterFrequency[c],
+				frequency[c],
 			)
 		}
 	}
 
-	if group.LetterFrequency['Z'] != 6 {
+	if frequency['Z'] != 6 {
 		t.Errorf("Z — expected: 6, got: %d",
-			group.LetterFrequency['Z'],
+			frequency['Z'],
+		)
+	}
+
+	// the group is not altered
+	if group.LetterFrequency != nil {
+		t.Error("expected no letter frequency stored in the group")
+	}
+}
+
+func TestShapeSignature(t *testing.T) {
+	type test struct {
+		name              string
+		group             groups.Group
+		expectedSignature Signature
+	}
+
+	tests := []test{
+		{
+			name:              "one letter",
+			group:             groups.Group{Segments: []string{"AAA"}},
+			expectedSignature: "3",
+		},
+		{
+			name:              "several segments",
+			group:             groups.Group{Segments: []string{"AAAAB", "BZ"}},
+			expectedSignature: "4-2-1",
+		},
+		{
+			// no padding zeros for the unused letters
+			name: "K4 (W separator)",
+			group: groups.Group{Segments: []string{
+				"OBKRUOXOGHULBSOLIFBB",
+				"TQSJQSSEKZZ",
+				"INFBNYPVTTMZFPK",
+			}},
+			expectedSignature: "5-4-4-3-3-3-3-2-2-2-2-2-2-1-1-1-1-1-1-1-1-1",
+		},
+		{
+			name:              "symbols",
+			group:             groups.Group{Segments: []string{"△△○", "◇△"}},
+			expectedSignature: "3-1-1",
+		},
+		{
+			name:              "empty group",
+			group:             groups.Group{},
+			expectedSignature: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			signature := ShapeSignature(tc.group)
+			if signature != tc.expectedSignature {
+				t.Errorf("expected: %s, got: %s", tc.expectedSignature, signature)
+			}
+
+			if tc.group.LetterFrequency != nil {
+				t.Error("expected no letter frequency stored in the group")
+			}
+		})
+	}
+}
+
+func TestShapeSignatureBuckets(t *testing.T) {
+	// groups are bucketed by shape, whatever their letters
+	buckets := make(map[Signature][]string)
+	for _, segment := range []string{"AAB", "XYY", "ABC", "QQZ", "DEF", "ZZZ"} {
+		signature := ShapeSignature(groups.Group{Segments: []string{segment}})
+		buckets[signature] = append(buckets[signature], segment)
+	}
+
+	expected := map[Signature]int{"2-1": 3, "1-1-1": 2, "3": 1}
+	for signature, count := range expected {
+		if len(buckets[signature]) != count {
+			t.Errorf("%s — expected: %d, got: %v", signature, count, buckets[signature])
+		}
+	}
+}
+
+func TestComputeLetterFrequencies(t *testing.T) {
+	collection := groups.Collection{
+		Groups: []groups.Group{
+			{Segments: []string{"AAB"}},
+			{Segments: []string{"XYY"}},
+		},
+	}
+
+	if !HaveIdenticalShapes(&collection) {
+		t.Fatal("expected identical shapes")
+	}
+
+	// HaveIdenticalShapes does not alter the groups
+	for _, group := range collection.Groups {
+		if group.LetterFrequency != nil {
+			t.Fatal("expected no letter frequency stored in the group")
+		}
+	}
+
+	ComputeLetterFrequencies(&collection)
+
+	if collection.Groups[0].LetterFrequency['A'] != 2 ||
+		collection.Groups[1].LetterFrequency['Y'] != 2 {
+		t.Errorf("unexpected letter frequencies: %v, %v",
+			collection.Groups[0].LetterFrequency,
+			collection.Groups[1].LetterFrequency,
 		)
 	}
 }
@@ -145,3 +250,58 @@ func BenchmarkFrequencyAnalysis(b *testing.B) {
 		HaveIdenticalShapes(&collection)
 	}
 }
+
+func TestParseSignature(t *testing.T) {
+	type test struct {
+		name              string
+		signature         string
+		expectedSignature Signature
+		expectedError     bool
+	}
+
+	tests := []test{
+		{
+			name:              "canonical",
+			signature:         "5-4-4-3",
+			expectedSignature: "5-4-4-3",
+		},
+		{
+			name:              "unordered",
+			signature:         " 3-5-4-4 ",
+			expectedSignature: "5-4-4-3",
+		},
+		{
+			name:              "one letter",
+			signature:         "3",
+			expectedSignature: "3",
+		},
+		{
+			name:          "empty",
+			signature:     "",
+			expectedError: true,
+		},
+		{
+			name:          "zero frequency",
+			signature:     "3-0",
+			expectedError: true,
+		},
+		{
+			name:          "not a number",
+			signature:     "3-A-1",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			signature, err := ParseSignature(tc.signature)
+			if tc.expectedError != (err != nil) {
+				t.Fatalf("error — expected: %t, got: %v", tc.expectedError, err)
+			}
+
+			if signature != tc.expectedSignature {
+				t.Errorf("expected: %s, got: %s", tc.expectedSignature, signature)
+			}
+		})
+	}
+}
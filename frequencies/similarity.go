@@ -0,0 +1,67 @@
+package frequencies
+
+import (
+	"math"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+// Profile returns the normalized letter frequency distribution shape
+// of a group: the relative frequencies of its letters, in descending order
+func Profile(group groups.Group) []float64 {
+	counts := shapeCounts(group)
+
+	total := 0
+	for _, v := range counts {
+		total += v
+	}
+
+	profile := make([]float64, len(counts))
+	for i, v := range counts {
+		profile[i] = float64(v) / float64(total)
+	}
+
+	return profile
+}
+
+// Similarity returns the similarity between the letter frequency
+// distribution shapes of two groups, from 0 (disjoint) to 1 (identical
+// normalized shapes). It is based on the L1 distance between their
+// profiles, so that groups of different lengths can be compared.
+func Similarity(a, b groups.Group) float64 {
+	profileA, profileB := Profile(a), Profile(b)
+
+	// pad the shortest profile with zeros
+	if len(profileA) < len(profileB) {
+		profileA, profileB = profileB, profileA
+	}
+
+	var distance float64
+	for i, v := range profileA {
+		if i < len(profileB) {
+			distance += math.Abs(v - profileB[i])
+		} else {
+			distance += v
+		}
+	}
+
+	// the L1 distance between two distributions is at most 2
+	return 1 - distance/2
+}
+
+// CollectionSimilarity returns the lowest similarity between
+// the groups of a collection
+func CollectionSimilarity(collection *groups.Collection) float64 {
+	similarity := 1.0
+
+	for i := 0; i < len(collection.Groups); i++ {
+		for j := i + 1; j < len(collection.Groups); j++ {
+			similarity = min(
+				similarity,
+				Similarity(collection.Groups[i], collection.Groups[j]),
+			)
+		}
+	}
+
+	return similarity
+}
@@ -0,0 +1,74 @@
+package frequencies
+
+import (
+	"math"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestSimilarity(t *testing.T) {
+	type test struct {
+		name       string
+		a          groups.Group
+		b          groups.Group
+		similarity float64
+	}
+
+	tests := []test{
+		{
+			name:       "identical shapes",
+			a:          groups.Group{Segments: []string{"AAB", "C"}},
+			b:          groups.Group{Segments: []string{"XYY", "Z"}},
+			similarity: 1,
+		},
+		{
+			name:       "proportional shapes (different lengths)",
+			a:          groups.Group{Segments: []string{"AAB"}},
+			b:          groups.Group{Segments: []string{"XXXXYY"}},
+			similarity: 1,
+		},
+		{
+			name: "close shapes",
+			a:    groups.Group{Segments: []string{"AAAB"}},
+			b:    groups.Group{Segments: []string{"AABC"}},
+			// profiles: .75 .25 / .5 .25 .25
+			// L1 distance: .25 + 0 + .25
+			similarity: 0.75,
+		},
+		{
+			name:       "different shapes",
+			a:          groups.Group{Segments: []string{"AAAA"}},
+			b:          groups.Group{Segments: []string{"ABCD"}},
+			similarity: 0.25,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			similarity := Similarity(tc.a, tc.b)
+			if math.Abs(similarity-tc.similarity) > 1e-9 {
+				t.Errorf("expected: %f, got: %f", tc.similarity, similarity)
+			}
+
+			// the similarity is symmetric
+			if Similarity(tc.b, tc.a) != similarity {
+				t.Errorf("asymmetric similarity: %f", Similarity(tc.b, tc.a))
+			}
+		})
+	}
+}
+
+func TestCollectionSimilarity(t *testing.T) {
+	collection := &groups.Collection{
+		Groups: []groups.Group{
+			{Segments: []string{"AAAB"}},
+			{Segments: []string{"XXXY"}},
+			{Segments: []string{"AABC"}},
+		},
+	}
+
+	if similarity := CollectionSimilarity(collection); math.Abs(similarity-0.75) > 1e-9 {
+		t.Errorf("expected: 0.75, got: %f", similarity)
+	}
+}
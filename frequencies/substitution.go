@@ -0,0 +1,215 @@
+package frequencies
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+var ErrDifferentShapes = errors.New(
+	"groups do not have the same letter frequency distribution shapes",
+)
+
+// RankClass gathers the letters of two groups that share the same
+// frequency: any letter of From can be mapped to any letter of To
+// (example: B ⇔ K for a frequency of 5, S/O ⇔ U/A for a frequency of 4)
+type RankClass struct {
+	Frequency int
+	From      []rune
+	To        []rune
+}
+
+// Substitution maps the letters of a group to the letters of another group
+type Substitution map[rune]rune
+
+// lettersPerFrequency returns the letters of a frequency table
+// indexed by their frequency (letters are sorted alphabetically)
+func lettersPerFrequency(frequency map[rune]int) map[int][]rune {
+	letters := make(map[int][]rune)
+
+	for letter, count := range frequency {
+		letters[count] = append(letters[count], letter)
+	}
+
+	for _, l := range letters {
+		sort.Slice(l, func(i, j int) bool {
+			return l[i] < l[j]
+		})
+	}
+
+	return letters
+}
+
+// DeriveRankClasses returns the classes of letters that can be substituted
+// to rewrite a group (from) in the alphabet of another group (to).
+// Classes are sorted by descending frequency.
+func DeriveRankClasses(from, to groups.Group) ([]RankClass, error) {
+	if ShapeSignature(from) != ShapeSignature(to) {
+		return nil, ErrDifferentShapes
+	}
+
+	fromLetters := lettersPerFrequency(LetterFrequency(from))
+	toLetters := lettersPerFrequency(LetterFrequency(to))
+
+	var classes []RankClass
+
+	for frequency, letters := range fromLetters {
+		classes = append(classes, RankClass{
+			Frequency: frequency,
+			From:      letters,
+			To:        toLetters[frequency],
+		})
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].Frequency > classes[j].Frequency
+	})
+
+	return classes, nil
+}
+
+// CountSubstitutions returns the number of letter-to-letter bijections
+// consistent with the rank classes (i.e., the product of the factorials
+// of the classes sizes)
+func CountSubstitutions(classes []RankClass) *big.Int {
+	count := big.NewInt(1)
+
+	for _, class := range classes {
+		count.Mul(count, new(big.Int).MulRange(1, int64(len(class.From))))
+	}
+
+	return count
+}
+
+// permuteLetters returns the permutations of letters in lexicographic
+// order of their indices, up to limit permutations
+func permuteLetters(letters []rune, limit int) [][]rune {
+	var permutations [][]rune
+
+	used := make([]bool, len(letters))
+	current := make([]rune, 0, len(letters))
+
+	var permute func()
+	permute = func() {
+		if len(permutations) >= limit {
+			return
+		}
+
+		if len(current) == len(letters) {
+			permutation := make([]rune, len(current))
+			copy(permutation, current)
+			permutations = append(permutations, permutation)
+			return
+		}
+
+		for i, letter := range letters {
+			if used[i] {
+				continue
+			}
+
+			used[i] = true
+			current = append(current, letter)
+			permute()
+			current = current[:len(current)-1]
+			used[i] = false
+		}
+	}
+
+	permute()
+
+	return permutations
+}
+
+// GenerateSubstitutions lists the substitutions consistent with the rank
+// classes, up to limit substitutions (the total number of substitutions
+// grows factorially with the size of the classes: see CountSubstitutions)
+func GenerateSubstitutions(classes []RankClass, limit int) []Substitution {
+	if limit <= 0 {
+		return nil
+	}
+
+	var substitutions []Substitution
+
+	var generate func(i int, substitution Substitution)
+	generate = func(i int, substitution Substitution) {
+		if len(substitutions) >= limit {
+			return
+		}
+
+		if i == len(classes) {
+			s := make(Substitution, len(substitution))
+			for k, v := range substitution {
+				s[k] = v
+			}
+			substitutions = append(substitutions, s)
+			return
+		}
+
+		// the number of permutations of a class never needs to exceed
+		// the number of substitutions that can still be generated
+		for _, to := range permuteLetters(classes[i].To, limit-len(substitutions)) {
+			for j, from := range classes[i].From {
+				substitution[from] = to[j]
+			}
+			generate(i+1, substitution)
+		}
+	}
+
+	generate(0, make(Substitution))
+
+	return substitutions
+}
+
+// Apply rewrites segments using the substitution
+// (letters absent from the substitution are left unchanged)
+func (s Substitution) Apply(segments []string) []string {
+	rewritten := make([]string, len(segments))
+
+	for i, segment := range segments {
+		rewritten[i] = strings.Map(func(r rune) rune {
+			if v, ok := s[r]; ok {
+				return v
+			}
+			return r
+		}, segment)
+	}
+
+	return rewritten
+}
+
+// String returns the substitution sorted alphabetically
+// (example: "A→U B→K")
+func (s Substitution) String() string {
+	keys := make([]rune, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	mappings := make([]string, len(keys))
+	for i, k := range keys {
+		mappings[i] = fmt.Sprintf("%s→%s", string(k), string(s[k]))
+	}
+
+	return strings.Join(mappings, " ")
+}
+
+// String returns the rank class (example: "S/O ⇔ U/A")
+func (c RankClass) String() string {
+	join := func(letters []rune) string {
+		l := make([]string, len(letters))
+		for i, letter := range letters {
+			l[i] = string(letter)
+		}
+		return strings.Join(l, "/")
+	}
+
+	return fmt.Sprintf("%s ⇔ %s", join(c.From), join(c.To))
+}
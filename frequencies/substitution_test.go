@@ -0,0 +1,116 @@
+package frequencies
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestDeriveRankClasses(t *testing.T) {
+	type test struct {
+		name            string
+		from            groups.Group
+		to              groups.Group
+		expectedClasses []RankClass
+		expectedError   error
+	}
+
+	tests := []test{
+		{
+			name: "identical distribution shapes",
+			from: groups.Group{Segments: []string{"BBBAA", "C"}},
+			to:   groups.Group{Segments: []string{"KKK", "UUD"}},
+			expectedClasses: []RankClass{
+				{Frequency: 3, From: []rune{'B'}, To: []rune{'K'}},
+				{Frequency: 2, From: []rune{'A'}, To: []rune{'U'}},
+				{Frequency: 1, From: []rune{'C'}, To: []rune{'D'}},
+			},
+		},
+		{
+			name: "letters sharing a frequency",
+			from: groups.Group{Segments: []string{"SSOOB"}},
+			to:   groups.Group{Segments: []string{"UAUAK"}},
+			expectedClasses: []RankClass{
+				{Frequency: 2, From: []rune{'O', 'S'}, To: []rune{'A', 'U'}},
+				{Frequency: 1, From: []rune{'B'}, To: []rune{'K'}},
+			},
+		},
+		{
+			name:          "different distribution shapes",
+			from:          groups.Group{Segments: []string{"AAB"}},
+			to:            groups.Group{Segments: []string{"CDE"}},
+			expectedError: ErrDifferentShapes,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			classes, err := DeriveRankClasses(tc.from, tc.to)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(classes, tc.expectedClasses) {
+				t.Errorf("expected: %v, got: %v", tc.expectedClasses, classes)
+			}
+		})
+	}
+}
+
+func TestGenerateSubstitutions(t *testing.T) {
+	from := groups.Group{Segments: []string{"SSOOBIN"}}
+	to := groups.Group{Segments: []string{"UAUAKCD"}}
+
+	classes, err := DeriveRankClasses(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2! (S/O ⇔ U/A) × 3! (B/I/N ⇔ K/C/D)
+	if count := CountSubstitutions(classes); count.Int64() != 12 {
+		t.Errorf("count — expected: 12, got: %d", count.Int64())
+	}
+
+	substitutions := GenerateSubstitutions(classes, 100)
+	if len(substitutions) != 12 {
+		t.Fatalf("substitutions — expected: 12, got: %d", len(substitutions))
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range substitutions {
+		if seen[s.String()] {
+			t.Errorf("duplicate substitution: %s", s)
+		}
+		seen[s.String()] = true
+
+		// rewriting must produce the letters of the target group
+		rewritten := s.Apply(from.Segments)
+		if !reflect.DeepEqual(
+			LetterFrequency(groups.Group{Segments: rewritten}),
+			LetterFrequency(to),
+		) {
+			t.Errorf("%s — unexpected rewrite: %v", s, rewritten)
+		}
+	}
+
+	if limited := GenerateSubstitutions(classes, 5); len(limited) != 5 {
+		t.Errorf("limited substitutions — expected: 5, got: %d", len(limited))
+	}
+}
+
+func TestSubstitutionApply(t *testing.T) {
+	s := Substitution{'B': 'K', 'S': 'U', 'O': 'A'}
+
+	rewritten := s.Apply([]string{"BOSS", "BZ"})
+	expected := []string{"KAUU", "KZ"}
+
+	if !reflect.DeepEqual(rewritten, expected) {
+		t.Errorf("expected: %v, got: %v", expected, rewritten)
+	}
+
+	if s.String() != "B→K O→A S→U" {
+		t.Errorf("unexpected string: %s", s.String())
+	}
+}
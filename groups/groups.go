@@ -1,123 +1,423 @@
 package groups
 
 import (
+	"context"
 	"crypto/sha256"
-	"encoding/hex"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// GroupsGenerator generates collections of groups.
+// It is safe for concurrent use.
 type GroupsGenerator struct {
-	knownCollections []string
+	knownCollections *CollectionsStore
+
+	// context of the enumerations, if any: an enumeration stops
+	// (and returns the collections found so far) once it is done
+	ctx context.Context
+
+	// scope of the collections, if any: collections are only compared
+	// to the ones of the same scope (e.g., of the same job)
+	scope string
 }
 
+// number of steps of an enumeration between two checks of its context
+const cancellationCheckInterval = 1024
+
 type Group struct {
-	Segments        []string
+	Segments []string
+
+	// location of each segment in the ciphertext
+	// (nil: unknown, e.g., segments not split by helpers.SplitSegments)
+	Spans []Span
+
 	LetterFrequency map[rune]int
 }
 
+// Span is the location of a segment in the ciphertext
+// (offsets in symbols, the end being excluded)
+type Span struct {
+	Start int
+	End   int
+}
+
+// Segment is a segment of the ciphertext and its location
+type Segment struct {
+	Text string
+	Span Span
+}
+
+// HasSpans identifies whether the location of each segment
+// of the group is known or not
+func (g Group) HasSpans() bool {
+	return len(g.Spans) == len(g.Segments) && len(g.Spans) > 0
+}
+
+// toSegments returns segments whose location is unknown
+func toSegments(texts []string) []Segment {
+	segments := make([]Segment, len(texts))
+	for i, text := range texts {
+		segments[i] = Segment{Text: text}
+	}
+	return segments
+}
+
+// newGroup returns the group of segments
+// (with their locations if they are known)
+func newGroup(segments []Segment, located bool) Group {
+	group := Group{
+		Segments: make([]string, len(segments)),
+	}
+
+	if located {
+		group.Spans = make([]Span, len(segments))
+	}
+
+	for i, segment := range segments {
+		group.Segments[i] = segment.Text
+		if located {
+			group.Spans[i] = segment.Span
+		}
+	}
+
+	return group
+}
+
+// compareSegments orders segments by text, then by location
+func compareSegments(a, b Segment) int {
+	if c := strings.Compare(a.Text, b.Text); c != 0 {
+		return c
+	}
+	return a.Span.Start - b.Span.Start
+}
+
 // suitable groups of segments
 type Collection struct {
 	Groups []Group
 }
 
 func GetGroupsGenerator() *GroupsGenerator {
-	return &GroupsGenerator{}
+	return GetGroupsGeneratorWithLimit(0)
+}
+
+// GetGroupsGeneratorWithLimit returns a generator keeping track of
+// at most limit collections already processed (0: unlimited)
+func GetGroupsGeneratorWithLimit(limit int) *GroupsGenerator {
+	return &GroupsGenerator{
+		knownCollections: NewCollectionsStore(limit),
+	}
+}
+
+// WithContext returns a generator sharing the collections already processed
+// whose enumerations stop once the context is done
+// (the caller is expected to check the context)
+func (g *GroupsGenerator) WithContext(ctx context.Context) *GroupsGenerator {
+	generator := *g
+	generator.ctx = ctx
+	return &generator
+}
+
+// WithScope returns a generator sharing the collections already processed
+// whose collections are only compared to the ones of the same scope
+// (e.g., several workers sharing a generator, each scope being a job)
+func (g *GroupsGenerator) WithScope(scope string) *GroupsGenerator {
+	generator := *g
+	generator.scope = scope
+	return &generator
+}
+
+// canceler returns a function identifying whether the enumeration
+// should stop or not (the context is only checked at regular intervals)
+func (g *GroupsGenerator) canceler() func() bool {
+	steps := 0
+	canceled := false
+
+	return func() bool {
+		if g.ctx == nil || canceled {
+			return canceled
+		}
+
+		steps++
+		if steps%cancellationCheckInterval == 0 {
+			canceled = g.ctx.Err() != nil
+		}
+		return canceled
+	}
+}
+
+// GetDuplicatesCount returns the number of collections skipped
+// because they had already been processed
+func (g *GroupsGenerator) GetDuplicatesCount() uint64 {
+	return g.knownCollections.GetDuplicatesCount()
 }
 
 // isNewCollection ensures that collections of groups already processed
 // are not processed again
-func (g *GroupsGenerator) isNewCollection(segments map[uint][]string) bool {
+// (collections are compared by text: swapping identical segments located
+// at different places yields the same collection)
+func (g *GroupsGenerator) isNewCollection(segments map[uint][]Segment) bool {
 	var allSegments []string
 
 	// sort segments in each group
+	// (the locations only order identical segments)
 	for _, segmentsPerGroup := range segments {
-		sort.Strings(segmentsPerGroup)
-		allSegments = append(allSegments,
-			strings.Join(segmentsPerGroup, "."),
-		)
+		slices.SortFunc(segmentsPerGroup, compareSegments)
+
+		texts := make([]string, len(segmentsPerGroup))
+		for i, segment := range segmentsPerGroup {
+			texts[i] = segment.Text
+		}
+
+		allSegments = append(allSegments, strings.Join(texts, "."))
 	}
 
 	// sort groups
 	// (because A|B ⇔ B|A)
 	sort.Strings(allSegments)
 
-	h := sha256.New()
-	h.Write([]byte(strings.Join(allSegments, "/")))
-	allGroups := hex.EncodeToString(h.Sum(nil))
+	// the length of the scope delimits it
+	return g.knownCollections.Add(sha256.Sum256([]byte(
+		strconv.Itoa(len(g.scope)) + ":" + g.scope + strings.Join(allSegments, "/"),
+	)))
+}
 
-	if slices.Contains(g.knownCollections, allGroups) {
-		return false
-	}
+// GetPartitions returns the collections of groups that can _potentially_
+// have the same letter frequency distribution shapes, i.e., the partitions
+// of the segments into groups with the same number of letters.
+//
+// The segments are directly assigned to groups whose remaining capacity
+// can hold them, instead of enumerating their permutations.
+func (g *GroupsGenerator) GetPartitions(segments []string) []*Collection {
+	return g.getPartitions(toSegments(segments), false)
+}
+
+// GetSegmentPartitions returns the same collections as GetPartitions,
+// the groups carrying the location of their segments
+// (identical segments are interchangeable: the first group of a collection
+// holds the earliest one)
+func (g *GroupsGenerator) GetSegmentPartitions(segments []Segment) []*Collection {
+	return g.getPartitions(segments, true)
+}
 
-	g.knownCollections = append(g.knownCollections, allGroups)
-	return true
+// VisitSegmentPartitions calls visit with each collection returned by
+// GetSegmentPartitions, as soon as it is found: the collections are not
+// kept in memory
+func (g *GroupsGenerator) VisitSegmentPartitions(
+	segments []Segment,
+	visit func(collection *Collection),
+) {
+	g.visitPartitions(segments, true, visit)
 }
 
-// GetSuitableCollections returns collections of groups that can _potentially_
-// have the same letter frequency distribution shapes. More specifically, it
-// ensures that the groups have the same number of letters.
-func (g *GroupsGenerator) GetSuitableCollections(permutation []string) []*Collection {
-	var suitableCollections []*Collection
+func (g *GroupsGenerator) getPartitions(segments []Segment, located bool) []*Collection {
+	var collections []*Collection
+	g.visitPartitions(segments, located, func(collection *Collection) {
+		collections = append(collections, collection)
+	})
+	return collections
+}
+
+func (g *GroupsGenerator) visitPartitions(
+	segments []Segment,
+	located bool,
+	visit func(collection *Collection),
+) {
+	canceled := g.canceler()
 
-	totalSegmentsLength := func(permutation []string) int {
-		size := 0
-		for _, segment := range permutation {
-			size += len(segment)
+	// process the longest segments first to prune early
+	// (identical segments are kept adjacent)
+	// (lengths are numbers of symbols, which can be encoded on several bytes)
+	sorted := slices.Clone(segments)
+	sort.Slice(sorted, func(i, j int) bool {
+		lengthI := utf8.RuneCountInString(sorted[i].Text)
+		lengthJ := utf8.RuneCountInString(sorted[j].Text)
+		if lengthI != lengthJ {
+			return lengthI > lengthJ
 		}
-		return size
-	}(permutation)
+		return compareSegments(sorted[i], sorted[j]) < 0
+	})
+
+	lengths := make([]int, len(sorted))
+	totalSegmentsLength := 0
+	for i, segment := range sorted {
+		lengths[i] = utf8.RuneCountInString(segment.Text)
+		totalSegmentsLength += lengths[i]
+	}
 
-	for collectionSize := 2; collectionSize <= len(permutation); collectionSize++ {
+	for collectionSize := 2; collectionSize <= len(sorted); collectionSize++ {
 		if totalSegmentsLength%collectionSize != 0 {
 			continue
 		}
 
 		expectedGroupLength := totalSegmentsLength / collectionSize
-		segments := make(map[uint][]string)
+		if lengths[0] > expectedGroupLength {
+			// the longest segment cannot fit in any group:
+			// no larger collection can be suitable either
+			break
+		}
 
 		var (
-			validCollection   bool
-			indexMap          uint
-			actualGroupLength int
-			i                 int
+			groupsLengths  = make([]int, collectionSize)
+			assignments    = make([]int, len(sorted))
+			assignSegments func(i int)
 		)
 
-		for j, p := range permutation {
-			actualGroupLength += len(p)
-			validCollection = true
-
-			if actualGroupLength > expectedGroupLength {
-				// groups cannot be suitable (different lengths):
-				// skip
-				validCollection = false
-				break
-			} else if actualGroupLength == expectedGroupLength {
-				// group length corresponds to the expected size of a group:
-				// continue
-				segments[indexMap] = permutation[i : j+1]
-
-				actualGroupLength = 0
-				i = j + 1
-				indexMap++
+		assignSegments = func(i int) {
+			if canceled() {
+				return
+			}
+
+			if i == len(sorted) {
+				// all the groups are full since the total length
+				// is a multiple of the expected group length
+				segmentsPerGroup := make(map[uint][]Segment)
+				for j, segment := range sorted {
+					segmentsPerGroup[uint(assignments[j])] = append(
+						segmentsPerGroup[uint(assignments[j])],
+						segment,
+					)
+				}
+
+				if g.isNewCollection(segmentsPerGroup) {
+					groups := make([]Group, collectionSize)
+					for j := range groups {
+						groups[j] = newGroup(segmentsPerGroup[uint(j)], located)
+					}
+
+					visit(&Collection{Groups: groups})
+				}
+				return
+			}
+
+			// identical segments are assigned to groups in a non-decreasing
+			// order (swapping them would yield the same collection)
+			first := 0
+			if i > 0 && sorted[i].Text == sorted[i-1].Text {
+				first = assignments[i-1]
+			}
+
+			for j := first; j < collectionSize; j++ {
+				if groupsLengths[j]+lengths[i] > expectedGroupLength {
+					continue
+				}
+
+				assignments[i] = j
+				groupsLengths[j] += lengths[i]
+				assignSegments(i + 1)
+				groupsLengths[j] -= lengths[i]
+
+				// empty groups are interchangeable:
+				// only the first one is tried
+				if groupsLengths[j] == 0 {
+					break
+				}
 			}
 		}
 
-		if validCollection && g.isNewCollection(segments) {
-			var groups []Group
+		assignSegments(0)
 
-			for _, v := range segments {
-				groups = append(groups, Group{
-					Segments: v,
-				})
+		if canceled() {
+			break
+		}
+	}
+}
+
+// GetAllPartitions returns all the collections of at least two groups
+// that can be made with the segments, whatever the lengths of the groups.
+// The number of collections grows quickly with the number of segments
+// (Bell numbers: 4140 for 8 segments, 115975 for 10 segments).
+func (g *GroupsGenerator) GetAllPartitions(segments []string) []*Collection {
+	var collections []*Collection
+	g.visitAllPartitions(toSegments(segments), false, func(collection *Collection) {
+		collections = append(collections, collection)
+	})
+	return collections
+}
+
+// GetAllSegmentPartitions returns the same collections as GetAllPartitions,
+// the groups carrying the location of their segments
+func (g *GroupsGenerator) GetAllSegmentPartitions(segments []Segment) []*Collection {
+	var collections []*Collection
+	g.VisitAllSegmentPartitions(segments, func(collection *Collection) {
+		collections = append(collections, collection)
+	})
+	return collections
+}
+
+// VisitAllSegmentPartitions calls visit with each collection returned by
+// GetAllSegmentPartitions, as soon as it is found: the collections are not
+// kept in memory
+func (g *GroupsGenerator) VisitAllSegmentPartitions(
+	segments []Segment,
+	visit func(collection *Collection),
+) {
+	g.visitAllPartitions(segments, true, visit)
+}
+
+func (g *GroupsGenerator) visitAllPartitions(
+	segments []Segment,
+	located bool,
+	visit func(collection *Collection),
+) {
+	canceled := g.canceler()
+
+	// identical segments are kept adjacent
+	sorted := slices.Clone(segments)
+	slices.SortFunc(sorted, compareSegments)
+
+	var (
+		assignments    = make([]int, len(sorted))
+		assignSegments func(i, groupsCount int)
+	)
+
+	assignSegments = func(i, groupsCount int) {
+		if canceled() {
+			return
+		}
+
+		if i == len(sorted) {
+			if groupsCount < 2 {
+				return
+			}
+
+			segmentsPerGroup := make(map[uint][]Segment)
+			for j, segment := range sorted {
+				segmentsPerGroup[uint(assignments[j])] = append(
+					segmentsPerGroup[uint(assignments[j])],
+					segment,
+				)
 			}
 
-			suitableCollections = append(suitableCollections, &Collection{
-				Groups: groups,
-			})
+			if g.isNewCollection(segmentsPerGroup) {
+				groups := make([]Group, groupsCount)
+				for j := range groups {
+					groups[j] = newGroup(segmentsPerGroup[uint(j)], located)
+				}
+
+				visit(&Collection{Groups: groups})
+			}
+			return
+		}
+
+		// identical segments are assigned to groups in a non-decreasing order
+		first := 0
+		if i > 0 && sorted[i].Text == sorted[i-1].Text {
+			first = assignments[i-1]
+		}
+
+		// a segment joins an existing group or starts a new one
+		for j := first; j <= groupsCount; j++ {
+			assignments[i] = j
+			if j == groupsCount {
+				assignSegments(i+1, groupsCount+1)
+			} else {
+				assignSegments(i+1, groupsCount)
+			}
 		}
 	}
 
-	return suitableCollections
+	assignSegments(0, 0)
 }
@@ -1,7 +1,12 @@
 package groups
 
 import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestGroups(t *testing.T) {
@@ -18,26 +23,28 @@ func TestGroups(t *testing.T) {
 			groupsCount: 0,
 		},
 		{
-			name:        "2 collections of groups",
+			name:        "4 collections of groups",
 			permutation: []string{"AA", "BB", "CC", "DD"},
 
 			// expected groups:
 			// AA | BB | CC | DD
 			// AA BB | CC DD
-			groupsCount: 2,
+			// AA CC | BB DD
+			// AA DD | BB CC
+			groupsCount: 4,
 		},
 		{
-			name:        "3 collections of groups",
+			name:        "26 collections of groups",
 			permutation: []string{"AA", "BB", "CC", "DD", "EE", "FF"},
 
 			// expected groups:
 			// AA | BB | CC | DD | EE | FF
-			// AA BB | CC DD | EE FF
-			// AA BB CC | DD EE FF
-			groupsCount: 3,
+			// 15 collections of 3 pairs (e.g., AA BB | CC DD | EE FF)
+			// 10 collections of 2 triples (e.g., AA BB CC | DD EE FF)
+			groupsCount: 26,
 		},
 		{
-			name: "1 collection of groups (K4)",
+			name: "2 collections of groups (K4)",
 			permutation: []string{
 				"INFBNYPVTTMZFPK",
 				"OBKRUOXOGHULBSOLIFBB",
@@ -46,14 +53,20 @@ func TestGroups(t *testing.T) {
 				"FLRVQQPRNGKSSOT",
 				"GDKZXTJCDIGKUHUAUEKCAR",
 			},
-			groupsCount: 1,
+
+			// expected groups:
+			// ATJKLUDIA FLRVQQPRNGKSSOT GDKZXTJCDIGKUHUAUEKCAR |
+			//   INFBNYPVTTMZFPK OBKRUOXOGHULBSOLIFBB TQSJQSSEKZZ
+			// ATJKLUDIA GDKZXTJCDIGKUHUAUEKCAR INFBNYPVTTMZFPK |
+			//   FLRVQQPRNGKSSOT OBKRUOXOGHULBSOLIFBB TQSJQSSEKZZ
+			groupsCount: 2,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			groups := GetGroupsGenerator().
-				GetSuitableCollections(tc.permutation)
+				GetPartitions(tc.permutation)
 			if uint(len(groups)) != tc.groupsCount {
 				t.Errorf("expected: %d, got: %d",
 					tc.groupsCount,
@@ -63,3 +76,296 @@ func TestGroups(t *testing.T) {
 		})
 	}
 }
+
+// getSuitableCollections returns the collections of groups with the same
+// number of letters made of contiguous runs of a permutation of the segments
+// (reference of GetPartitions, applied to all the permutations)
+func (g *GroupsGenerator) getSuitableCollections(permutation []string) []*Collection {
+	var suitableCollections []*Collection
+
+	totalSegmentsLength := func(permutation []string) int {
+		size := 0
+		for _, segment := range permutation {
+			size += utf8.RuneCountInString(segment)
+		}
+		return size
+	}(permutation)
+
+	for collectionSize := 2; collectionSize <= len(permutation); collectionSize++ {
+		if totalSegmentsLength%collectionSize != 0 {
+			continue
+		}
+
+		expectedGroupLength := totalSegmentsLength / collectionSize
+		segments := make(map[uint][]Segment)
+
+		var (
+			validCollection   bool
+			indexMap          uint
+			actualGroupLength int
+			i                 int
+		)
+
+		for j, p := range permutation {
+			actualGroupLength += utf8.RuneCountInString(p)
+			validCollection = true
+
+			if actualGroupLength > expectedGroupLength {
+				// groups cannot be suitable (different lengths):
+				// skip
+				validCollection = false
+				break
+			} else if actualGroupLength == expectedGroupLength {
+				// group length corresponds to the expected size of a group:
+				// continue
+				// (segments are copied: sorting them must not alter
+				// the permutation, shared by the other collection sizes)
+				segments[indexMap] = toSegments(permutation[i : j+1])
+
+				actualGroupLength = 0
+				i = j + 1
+				indexMap++
+			}
+		}
+
+		if validCollection && g.isNewCollection(segments) {
+			var groups []Group
+
+			for _, v := range segments {
+				groups = append(groups, newGroup(v, false))
+			}
+
+			suitableCollections = append(suitableCollections, &Collection{
+				Groups: groups,
+			})
+		}
+	}
+
+	return suitableCollections
+}
+
+// canonicalCollections returns a sorted representation of collections
+func canonicalCollections(collections []*Collection) []string {
+	var canonical []string
+
+	for _, collection := range collections {
+		var gs []string
+		for _, group := range collection.Groups {
+			segments := make([]string, len(group.Segments))
+			copy(segments, group.Segments)
+			sort.Strings(segments)
+			gs = append(gs, strings.Join(segments, "."))
+		}
+		sort.Strings(gs)
+		canonical = append(canonical, strings.Join(gs, "/"))
+	}
+
+	sort.Strings(canonical)
+	return canonical
+}
+
+// permutations returns all the orderings of the segments
+func permutations(segments []string) [][]string {
+	if len(segments) <= 1 {
+		return [][]string{segments}
+	}
+
+	var all [][]string
+	for i := range segments {
+		rest := make([]string, 0, len(segments)-1)
+		rest = append(rest, segments[:i]...)
+		rest = append(rest, segments[i+1:]...)
+
+		for _, p := range permutations(rest) {
+			all = append(all, append([]string{segments[i]}, p...))
+		}
+	}
+
+	return all
+}
+
+func TestPartitions(t *testing.T) {
+	tests := [][]string{
+		{"AAAAAAAAAAAAAAAAAAAAAAA", "BB"},
+		{"AA", "BB", "CC", "DD"},
+		{"AA", "BB", "CC", "DD", "EE", "FF"},
+		{"A", "BB", "CCC", "D", "EE", "F"},
+		{"AB", "AB", "AB", "CD", "E", "F"},
+		{"A", "A", "A", "A", "BB", "BB"},
+		{"ABC", "DE", "F", "GH", "IJK", "L", "M"},
+		{
+			"INFBNYPVTTMZFPK",
+			"OBKRUOXOGHULBSOLIFBB",
+			"TQSJQSSEKZZ",
+			"ATJKLUDIA",
+			"FLRVQQPRNGKSSOT",
+			"GDKZXTJCDIGKUHUAUEKCAR",
+		},
+	}
+
+	for _, segments := range tests {
+		t.Run(strings.Join(segments, "|"), func(t *testing.T) {
+			// reference: contiguous runs of all the permutations
+			generator := GetGroupsGenerator()
+			var expected []*Collection
+			for _, p := range permutations(segments) {
+				expected = append(expected, generator.getSuitableCollections(p)...)
+			}
+
+			partitions := GetGroupsGenerator().GetPartitions(segments)
+
+			if !reflect.DeepEqual(
+				canonicalCollections(expected),
+				canonicalCollections(partitions),
+			) {
+				t.Errorf("expected: %v, got: %v",
+					canonicalCollections(expected),
+					canonicalCollections(partitions),
+				)
+			}
+		})
+	}
+}
+
+func BenchmarkPartitions(b *testing.B) {
+	segments := []string{
+		"OBKRUOXOG", "HULB", "OLIFBB", "FLRVQQPR", "NGK", "SOTWT",
+		"QSJQ", "EKZZWA", "TJKLUDIA", "INFBNYPV", "TTMZ", "FPKW",
+	}
+
+	for n := 0; n < b.N; n++ {
+		GetGroupsGenerator().GetPartitions(segments)
+	}
+}
+
+func TestAllPartitions(t *testing.T) {
+	type test struct {
+		name             string
+		segments         []string
+		collectionsCount int
+	}
+
+	tests := []test{
+		// Bell numbers minus the single-group collection
+		{name: "3 segments", segments: []string{"A", "BB", "CCC"}, collectionsCount: 4},
+		{name: "4 segments", segments: []string{"A", "BB", "CCC", "D"}, collectionsCount: 14},
+		{name: "5 segments", segments: []string{"A", "B", "C", "D", "E"}, collectionsCount: 51},
+		// A A | B; A | A B; A | A | B
+		{name: "identical segments", segments: []string{"A", "A", "B"}, collectionsCount: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			collections := GetGroupsGenerator().GetAllPartitions(tc.segments)
+			if len(collections) != tc.collectionsCount {
+				t.Errorf("expected: %d, got: %d: %v",
+					tc.collectionsCount,
+					len(collections),
+					canonicalCollections(collections),
+				)
+			}
+		})
+	}
+}
+
+func TestPartitionsSymbols(t *testing.T) {
+	type test struct {
+		name             string
+		segments         []string
+		collectionsCount int
+	}
+
+	tests := []test{
+		// lengths are numbers of symbols, not bytes
+		{name: "multibyte and ASCII symbols", segments: []string{"△○", "AB"}, collectionsCount: 1},
+		{name: "multibyte symbols", segments: []string{"△○◇", "☐⊕", "△"}, collectionsCount: 1},
+		{name: "different lengths", segments: []string{"△○◇", "AB"}, collectionsCount: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			collections := GetGroupsGenerator().GetPartitions(tc.segments)
+			if len(collections) != tc.collectionsCount {
+				t.Errorf("expected: %d, got: %d: %v",
+					tc.collectionsCount,
+					len(collections),
+					canonicalCollections(collections),
+				)
+			}
+		})
+	}
+}
+
+func TestSegmentPartitions(t *testing.T) {
+	segments := []Segment{
+		{Text: "AB", Span: Span{Start: 0, End: 2}},
+		{Text: "AB", Span: Span{Start: 3, End: 5}},
+		{Text: "CD", Span: Span{Start: 6, End: 8}},
+		{Text: "CD", Span: Span{Start: 9, End: 11}},
+	}
+
+	// identical segments located at different places are interchangeable:
+	// AB AB | CD CD, AB CD | AB CD, AB | AB | CD | CD, etc.
+	located := GetGroupsGenerator().GetSegmentPartitions(segments)
+	unlocated := GetGroupsGenerator().GetPartitions([]string{"AB", "AB", "CD", "CD"})
+
+	if len(located) != len(unlocated) {
+		t.Errorf("collections — expected: %d, got: %d",
+			len(unlocated),
+			len(located),
+		)
+	}
+
+	all := GetGroupsGenerator().GetAllSegmentPartitions(segments)
+	allUnlocated := GetGroupsGenerator().GetAllPartitions([]string{"AB", "AB", "CD", "CD"})
+
+	if len(all) != len(allUnlocated) {
+		t.Errorf("all collections — expected: %d, got: %d",
+			len(allUnlocated),
+			len(all),
+		)
+	}
+
+	for _, collection := range located {
+		for _, group := range collection.Groups {
+			if !group.HasSpans() {
+				t.Fatalf("expected located segments: %+v", group)
+			}
+
+			for i, span := range group.Spans {
+				if group.Segments[i] != []string{"AB", "AB", "CD", "CD"}[span.Start/3] {
+					t.Errorf("segment %s located at %d", group.Segments[i], span.Start)
+				}
+			}
+		}
+	}
+
+	for _, collection := range unlocated {
+		for _, group := range collection.Groups {
+			if group.Spans != nil {
+				t.Errorf("expected no locations: %+v", group)
+			}
+		}
+	}
+}
+
+func TestPartitionsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Bell numbers: 4213597 partitions of 12 segments
+	segments := make([]string, 12)
+	for i := range segments {
+		segments[i] = string(rune('A' + i))
+	}
+
+	generator := GetGroupsGenerator().WithContext(ctx)
+
+	if collections := generator.GetAllPartitions(segments); len(collections) >= 4213597 {
+		t.Errorf("expected the enumeration to stop, got: %d collections", len(collections))
+	}
+
+	if collections := generator.GetPartitions(segments); len(collections) > cancellationCheckInterval {
+		t.Errorf("expected the enumeration to stop, got: %d collections", len(collections))
+	}
+}
@@ -0,0 +1,74 @@
+package groups
+
+import (
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+)
+
+// CollectionsStore keeps track of the collections already processed.
+// It is safe for concurrent use.
+type CollectionsStore struct {
+	mu sync.Mutex
+
+	// maximum number of collections kept in memory
+	// (0: unlimited)
+	limit int
+
+	// when the limit is reached, the most recent collections are kept
+	// while the oldest ones are forgotten (and could be processed again)
+	current  map[[sha256.Size]byte]struct{}
+	previous map[[sha256.Size]byte]struct{}
+
+	// number of collections already processed
+	duplicatesCount atomic.Uint64
+}
+
+// NewCollectionsStore returns a store keeping at most limit
+// collections in memory (0: unlimited)
+func NewCollectionsStore(limit int) *CollectionsStore {
+	return &CollectionsStore{
+		limit:   limit,
+		current: make(map[[sha256.Size]byte]struct{}),
+	}
+}
+
+// Add adds a collection to the store and returns whether it is new or not
+func (s *CollectionsStore) Add(collection [sha256.Size]byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, inCurrent := s.current[collection]
+	_, inPrevious := s.previous[collection]
+	if inCurrent || inPrevious {
+		s.duplicatesCount.Add(1)
+		return false
+	}
+
+	// each generation holds half of the limit
+	// (a limit of 1 keeps a single generation)
+	if s.limit > 0 && len(s.current) >= max(s.limit/2, 1) {
+		s.previous = nil
+		if s.limit > 1 {
+			s.previous = s.current
+		}
+		s.current = make(map[[sha256.Size]byte]struct{})
+	}
+
+	s.current[collection] = struct{}{}
+	return true
+}
+
+// Len returns the number of collections kept in memory
+func (s *CollectionsStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.current) + len(s.previous)
+}
+
+// GetDuplicatesCount returns the number of collections skipped
+// because they had already been processed
+func (s *CollectionsStore) GetDuplicatesCount() uint64 {
+	return s.duplicatesCount.Load()
+}
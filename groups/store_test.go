@@ -0,0 +1,132 @@
+package groups
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCollectionsStore(t *testing.T) {
+	store := NewCollectionsStore(0)
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 1_000; j++ {
+			isNew := store.Add(sha256.Sum256([]byte(fmt.Sprint(j))))
+			if isNew != (i == 0) {
+				t.Fatalf("collection %d (pass %d) — expected new: %t, got: %t",
+					j, i, i == 0, isNew,
+				)
+			}
+		}
+	}
+
+	if store.GetDuplicatesCount() != 1_000 {
+		t.Errorf("duplicates — expected: 1000, got: %d", store.GetDuplicatesCount())
+	}
+}
+
+func TestCollectionsStoreLimit(t *testing.T) {
+	store := NewCollectionsStore(100)
+
+	for j := 0; j < 1_000; j++ {
+		store.Add(sha256.Sum256([]byte(fmt.Sprint(j))))
+
+		if store.Len() > 100 {
+			t.Fatalf("length — expected: <= 100, got: %d", store.Len())
+		}
+	}
+
+	// the most recent collections are still known
+	if store.Add(sha256.Sum256([]byte(fmt.Sprint(999)))) {
+		t.Error("expected a known collection")
+	}
+
+	// the oldest ones have been forgotten
+	if !store.Add(sha256.Sum256([]byte(fmt.Sprint(0)))) {
+		t.Error("expected a forgotten collection")
+	}
+}
+
+func TestGroupsGeneratorConcurrency(t *testing.T) {
+	var wg sync.WaitGroup
+
+	generator := GetGroupsGenerator()
+	collections := make([]int, 8)
+
+	for w := range collections {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			collections[w] = len(generator.GetPartitions(
+				[]string{"AA", "BB", "CC", "DD", "EE", "FF"},
+			))
+		}(w)
+	}
+	wg.Wait()
+
+	// each collection is returned by a single worker
+	total := 0
+	for _, c := range collections {
+		total += c
+	}
+
+	if total != 26 {
+		t.Errorf("collections — expected: 26, got: %d", total)
+	}
+
+	if generator.GetDuplicatesCount() != uint64(26*(len(collections)-1)) {
+		t.Errorf("duplicates — expected: %d, got: %d",
+			26*(len(collections)-1),
+			generator.GetDuplicatesCount(),
+		)
+	}
+}
+
+func TestCollectionsStoreOddLimit(t *testing.T) {
+	for _, limit := range []int{1, 3, 101} {
+		store := NewCollectionsStore(limit)
+
+		for j := 0; j < 1_000; j++ {
+			store.Add(sha256.Sum256([]byte(fmt.Sprint(j))))
+
+			if store.Len() > limit {
+				t.Fatalf("length (limit %d) — expected: <= %d, got: %d",
+					limit,
+					limit,
+					store.Len(),
+				)
+			}
+		}
+
+		// the most recent collection is still known
+		if store.Add(sha256.Sum256([]byte(fmt.Sprint(999)))) {
+			t.Errorf("limit %d: expected a known collection", limit)
+		}
+	}
+}
+
+func TestGroupsGeneratorScopes(t *testing.T) {
+	segments := []string{"AA", "BB", "CC", "DD", "EE", "FF"}
+	generator := GetGroupsGenerator()
+
+	// the collections of distinct scopes are not compared
+	for _, scope := range []string{"W", "X"} {
+		collections := generator.WithScope(scope).GetPartitions(segments)
+		if len(collections) != 26 {
+			t.Errorf("collections (scope %s) — expected: 26, got: %d",
+				scope,
+				len(collections),
+			)
+		}
+	}
+
+	// the collections of a scope are only processed once
+	if len(generator.WithScope("W").GetPartitions(segments)) != 0 {
+		t.Error("expected no new collection")
+	}
+
+	if generator.GetDuplicatesCount() != 26 {
+		t.Errorf("duplicates — expected: 26, got: %d", generator.GetDuplicatesCount())
+	}
+}
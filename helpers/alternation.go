@@ -0,0 +1,458 @@
+package helpers
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+// AlternationPattern is the way groups follow one another
+// in the ciphertext
+type AlternationPattern string
+
+const (
+	// the groups repeat in the same order (e.g., 0,1,0,1,0,1)
+	StrictAlternation AlternationPattern = "strict"
+
+	// two consecutive segments never belong to the same group
+	// (e.g., 0,1,2,1,0,2)
+	CyclicAlternation AlternationPattern = "cyclic"
+
+	// the sequence reads the same backward (e.g., 0,1,1,0)
+	PalindromicAlternation AlternationPattern = "palindromic"
+
+	// the segments of each group are contiguous (e.g., 0,0,1,1)
+	BlockAlternation AlternationPattern = "block"
+
+	NoAlternation AlternationPattern = "none"
+)
+
+// Alternation describes the sequence of the groups in the ciphertext
+type Alternation struct {
+	// groups of the segments in the order they appear in the ciphertext,
+	// numbered in order of appearance (e.g., 0,1,0,1,0,1)
+	Sequence []int
+
+	// most specific pattern followed by the sequence
+	// (strict, cyclic, palindromic, block, or none): the patterns are
+	// not exclusive, the sequence can follow less specific ones as well
+	// (see Matches)
+	Pattern AlternationPattern
+
+	// probability that a random ordering of the segments follows
+	// the pattern, whether or not it follows a more specific one
+	// (1 for none)
+	Probability float64
+
+	// probability that a random ordering of the segments
+	// produces this very sequence
+	SequenceProbability float64
+
+	// number of groups (0: the groups of the sequence)
+	groupsCount int
+}
+
+// Matches identifies whether the sequence follows a pattern, e.g.,
+// a strictly alternating sequence of several groups is cyclic as well
+// (none is only matched by the sequences following no other pattern)
+func (a Alternation) Matches(pattern AlternationPattern) bool {
+	groupsCount := a.groupsCount
+	if groupsCount == 0 && len(a.Sequence) > 0 {
+		groupsCount = slices.Max(a.Sequence) + 1
+	}
+
+	switch pattern {
+	case StrictAlternation:
+		return isStrict(a.Sequence, groupsCount)
+	case CyclicAlternation:
+		return isCyclic(a.Sequence)
+	case PalindromicAlternation:
+		return isPalindromic(a.Sequence)
+	case BlockAlternation:
+		return isBlock(a.Sequence)
+	case NoAlternation:
+		return !a.Matches(StrictAlternation) &&
+			!a.Matches(CyclicAlternation) &&
+			!a.Matches(PalindromicAlternation) &&
+			!a.Matches(BlockAlternation)
+	}
+
+	return false
+}
+
+// PatternProbability returns the probability that a random ordering
+// of the segments follows a pattern (1 for none)
+func (a Alternation) PatternProbability(pattern AlternationPattern) float64 {
+	counts := segmentsPerGroup(a.Sequence)
+
+	if len(counts) == 0 {
+		return 1
+	}
+
+	arrangements := countArrangements(counts)
+
+	switch pattern {
+	case StrictAlternation:
+		return probability(countStrict(counts), arrangements)
+	case CyclicAlternation:
+		return probability(countCyclic(counts), arrangements)
+	case PalindromicAlternation:
+		return probability(countPalindromic(counts), arrangements)
+	case BlockAlternation:
+		return probability(countBlock(counts), arrangements)
+	}
+
+	return 1
+}
+
+// FormatSequence returns the sequence of the groups (e.g., "0,1,0,1")
+func (a Alternation) FormatSequence() string {
+	values := make([]string, len(a.Sequence))
+	for i, v := range a.Sequence {
+		values[i] = strconv.Itoa(v)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// groupsSequence returns the labels of the groups in the order
+// their segments appear in the ciphertext (e.g., { 0, 1, 0, 1 })
+func groupsSequence(ciphertext string, gs []groups.Group) []int {
+	located := len(gs) > 0
+	for _, group := range gs {
+		located = located && group.HasSpans()
+	}
+
+	if !located {
+		return matchGroupsSequence(ciphertext, gs)
+	}
+
+	type segment struct {
+		start   int
+		groupId int
+	}
+
+	var segments []segment
+	for i, group := range gs {
+		for _, span := range group.Spans {
+			segments = append(segments, segment{start: span.Start, groupId: i})
+		}
+	}
+
+	// the segments are ordered by their location in the ciphertext
+	slices.SortFunc(segments, func(a, b segment) int {
+		return a.start - b.start
+	})
+
+	sequence := make([]int, len(segments))
+	for i, segment := range segments {
+		sequence[i] = segment.groupId
+	}
+
+	return sequence
+}
+
+// matchGroupsSequence returns the labels of the groups whose segments'
+// locations are unknown, by matching the segments in the ciphertext
+// (at each position, the longest remaining segment is preferred,
+// and identical segments are matched as many times as they appear)
+func matchGroupsSequence(ciphertext string, gs []groups.Group) []int {
+	type segment struct {
+		text    []rune
+		groupId int
+		matched bool
+	}
+
+	var segments []*segment
+	for i, group := range gs {
+		for _, text := range group.Segments {
+			segments = append(segments, &segment{text: []rune(text), groupId: i})
+		}
+	}
+
+	slices.SortStableFunc(segments, func(a, b *segment) int {
+		return len(b.text) - len(a.text)
+	})
+
+	var sequence []int
+
+	symbols := []rune(ciphertext)
+	for offset := 0; offset < len(symbols); {
+		matched := false
+
+		for _, segment := range segments {
+			if segment.matched || len(segment.text) == 0 ||
+				!slices.Equal(
+					symbols[offset:min(offset+len(segment.text), len(symbols))],
+					segment.text,
+				) {
+				continue
+			}
+
+			segment.matched = true
+			sequence = append(sequence, segment.groupId)
+			offset += len(segment.text)
+			matched = true
+			break
+		}
+
+		// separator
+		if !matched {
+			offset++
+		}
+	}
+
+	return sequence
+}
+
+// relabel numbers the groups of a sequence in order of appearance
+func relabel(sequence []int) []int {
+	labels := make(map[int]int)
+	relabeled := make([]int, len(sequence))
+
+	for i, group := range sequence {
+		if _, ok := labels[group]; !ok {
+			labels[group] = len(labels)
+		}
+		relabeled[i] = labels[group]
+	}
+
+	return relabeled
+}
+
+// isStrict identifies whether the groups of a sequence repeat in
+// the same order (the first groups all differ, then the cycle repeats)
+func isStrict(sequence []int, groupsCount int) bool {
+	if len(sequence) < groupsCount {
+		return false
+	}
+
+	for i := range sequence {
+		if i < groupsCount && slices.Contains(sequence[:i], sequence[i]) {
+			return false
+		}
+
+		if sequence[i] != sequence[i%groupsCount] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCyclic(sequence []int) bool {
+	for i := 1; i < len(sequence); i++ {
+		if sequence[i] == sequence[i-1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isPalindromic(sequence []int) bool {
+	for i, j := 0, len(sequence)-1; i < j; i, j = i+1, j-1 {
+		if sequence[i] != sequence[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isBlock(sequence []int) bool {
+	closed := make(map[int]bool)
+
+	for i, group := range sequence {
+		if closed[group] {
+			return false
+		}
+
+		if i+1 < len(sequence) && sequence[i+1] != group {
+			closed[group] = true
+		}
+	}
+
+	return true
+}
+
+func factorial(n int) *big.Int {
+	return new(big.Int).MulRange(1, int64(max(n, 1)))
+}
+
+// segmentsPerGroup returns the number of segments of each group
+// of a sequence
+func segmentsPerGroup(sequence []int) []int {
+	var counts []int
+	for _, group := range sequence {
+		for group >= len(counts) {
+			counts = append(counts, 0)
+		}
+		counts[group]++
+	}
+
+	return counts
+}
+
+// countArrangements returns the number of distinct orderings of segments
+// given the number of segments per group
+func countArrangements(counts []int) *big.Int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	arrangements := factorial(total)
+	for _, count := range counts {
+		arrangements.Quo(arrangements, factorial(count))
+	}
+
+	return arrangements
+}
+
+// countStrict returns the number of orderings in which the groups
+// repeat in the same order
+func countStrict(counts []int) *big.Int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	groupsCount := len(counts)
+	if total < groupsCount {
+		return big.NewInt(0)
+	}
+
+	// the i-th group of the cycle has as many segments as positions
+	// congruent to i
+	expected := make([]int, groupsCount)
+	for i := range total {
+		expected[i%groupsCount]++
+	}
+
+	actual := slices.Clone(counts)
+	slices.Sort(actual)
+	slices.Sort(expected)
+	if !slices.Equal(actual, expected) {
+		return big.NewInt(0)
+	}
+
+	// groups with the same number of segments are interchangeable
+	strict := big.NewInt(1)
+	for i := 0; i < len(actual); {
+		j := i
+		for j < len(actual) && actual[j] == actual[i] {
+			j++
+		}
+		strict.Mul(strict, factorial(j-i))
+		i = j
+	}
+
+	return strict
+}
+
+// countCyclic returns the number of orderings in which two consecutive
+// segments never belong to the same group (inclusion–exclusion over
+// the number of runs of each group)
+func countCyclic(counts []int) *big.Int {
+	// polynomial in the total number of runs:
+	// coefficient of the runs count k
+	polynomial := []*big.Rat{big.NewRat(1, 1)}
+
+	for _, count := range counts {
+		next := make([]*big.Rat, len(polynomial)+count)
+		for i := range next {
+			next[i] = new(big.Rat)
+		}
+
+		for runs := 1; runs <= count; runs++ {
+			// (-1)^(count-runs) C(count-1, runs-1) / runs!
+			term := new(big.Rat).SetFrac(
+				new(big.Int).Binomial(int64(count-1), int64(runs-1)),
+				factorial(runs),
+			)
+			if (count-runs)%2 == 1 {
+				term.Neg(term)
+			}
+
+			for k, coefficient := range polynomial {
+				next[k+runs].Add(next[k+runs], new(big.Rat).Mul(coefficient, term))
+			}
+		}
+
+		polynomial = next
+	}
+
+	cyclic := new(big.Rat)
+	for k, coefficient := range polynomial {
+		cyclic.Add(cyclic, new(big.Rat).Mul(coefficient, new(big.Rat).SetInt(factorial(k))))
+	}
+
+	return cyclic.Num()
+}
+
+// countPalindromic returns the number of orderings reading
+// the same backward
+func countPalindromic(counts []int) *big.Int {
+	halves := make([]int, len(counts))
+	odd := 0
+
+	for i, count := range counts {
+		halves[i] = count / 2
+		odd += count % 2
+	}
+
+	if odd > 1 {
+		return big.NewInt(0)
+	}
+
+	return countArrangements(halves)
+}
+
+// countBlock returns the number of orderings in which the segments
+// of each group are contiguous
+func countBlock(counts []int) *big.Int {
+	return factorial(len(counts))
+}
+
+func probability(count, total *big.Int) float64 {
+	p, _ := new(big.Rat).SetFrac(count, total).Float64()
+	return p
+}
+
+// AnalyzeAlternation returns the sequence of the groups in the ciphertext,
+// its pattern, and how likely it is if the segments were randomly ordered
+func AnalyzeAlternation(ciphertext string, gs []groups.Group) Alternation {
+	sequence := relabel(groupsSequence(ciphertext, gs))
+
+	alternation := Alternation{
+		Sequence:            sequence,
+		Pattern:             NoAlternation,
+		Probability:         1,
+		SequenceProbability: 1,
+		groupsCount:         len(gs),
+	}
+
+	if len(sequence) == 0 {
+		return alternation
+	}
+
+	alternation.SequenceProbability = probability(
+		big.NewInt(1),
+		countArrangements(segmentsPerGroup(sequence)),
+	)
+
+	// the most specific pattern (the patterns are ordered by specificity)
+	for _, pattern := range alternationPatterns {
+		if alternation.Matches(pattern) {
+			alternation.Pattern = pattern
+			alternation.Probability = alternation.PatternProbability(pattern)
+			break
+		}
+	}
+
+	return alternation
+}
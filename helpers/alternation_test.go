@@ -0,0 +1,287 @@
+package helpers
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestAnalyzeAlternation(t *testing.T) {
+	type test struct {
+		name                string
+		ciphertext          string
+		groups              []groups.Group
+		expectedSequence    []int
+		expectedPattern     AlternationPattern
+		expectedProbability float64
+	}
+
+	tests := []test{
+		{
+			// 2 strict orderings (ABABAB, BABABA) out of 20
+			name:       "strict",
+			ciphertext: "AAXYYXBBXZZXCCXWW",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB", "CC"}},
+				{Segments: []string{"YY", "ZZ", "WW"}},
+			},
+			expectedSequence:    []int{0, 1, 0, 1, 0, 1},
+			expectedPattern:     StrictAlternation,
+			expectedProbability: 2.0 / 20,
+		},
+		{
+			// the groups are numbered in order of appearance
+			name:       "strict (second group first)",
+			ciphertext: "YYXAAXZZXBB",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB"}},
+				{Segments: []string{"YY", "ZZ"}},
+			},
+			expectedSequence:    []int{0, 1, 0, 1},
+			expectedPattern:     StrictAlternation,
+			expectedProbability: 2.0 / 6,
+		},
+		{
+			// 30 orderings without repetitions (Smirnov words) out of 90
+			name:       "cyclic",
+			ciphertext: "AAXYYXOOXZZXBBXPP",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB"}},
+				{Segments: []string{"YY", "ZZ"}},
+				{Segments: []string{"OO", "PP"}},
+			},
+			expectedSequence:    []int{0, 1, 2, 1, 0, 2},
+			expectedPattern:     CyclicAlternation,
+			expectedProbability: 30.0 / 90,
+		},
+		{
+			// 2 palindromes (ABBA, BAAB) out of 6
+			name:       "palindromic",
+			ciphertext: "AAXYYXZZXBB",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB"}},
+				{Segments: []string{"YY", "ZZ"}},
+			},
+			expectedSequence:    []int{0, 1, 1, 0},
+			expectedPattern:     PalindromicAlternation,
+			expectedProbability: 2.0 / 6,
+		},
+		{
+			// 2 block orderings (AAABBB, BBBAAA) out of 20
+			name:       "block",
+			ciphertext: "AAXBBXCCXYYXZZXWW",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB", "CC"}},
+				{Segments: []string{"YY", "ZZ", "WW"}},
+			},
+			expectedSequence:    []int{0, 0, 0, 1, 1, 1},
+			expectedPattern:     BlockAlternation,
+			expectedProbability: 2.0 / 20,
+		},
+		{
+			name:       "no pattern",
+			ciphertext: "AAXBBXYYXCCXZZXWW",
+			groups: []groups.Group{
+				{Segments: []string{"AA", "BB", "CC"}},
+				{Segments: []string{"YY", "ZZ", "WW"}},
+			},
+			expectedSequence:    []int{0, 0, 1, 0, 1, 1},
+			expectedPattern:     NoAlternation,
+			expectedProbability: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			alternation := AnalyzeAlternation(tc.ciphertext, tc.groups)
+
+			if !slices.Equal(alternation.Sequence, tc.expectedSequence) {
+				t.Errorf("sequence — expected: %v, got: %v",
+					tc.expectedSequence,
+					alternation.Sequence,
+				)
+			}
+
+			if alternation.Pattern != tc.expectedPattern {
+				t.Errorf("pattern — expected: %s, got: %s",
+					tc.expectedPattern,
+					alternation.Pattern,
+				)
+			}
+
+			if math.Abs(alternation.Probability-tc.expectedProbability) > 1e-9 {
+				t.Errorf("probability — expected: %f, got: %f",
+					tc.expectedProbability,
+					alternation.Probability,
+				)
+			}
+		})
+	}
+}
+
+func TestCountCyclic(t *testing.T) {
+	type test struct {
+		counts   []int
+		expected int64
+	}
+
+	tests := []test{
+		{counts: []int{1, 1}, expected: 2},
+		{counts: []int{2, 1}, expected: 1}, // ABA
+		{counts: []int{3, 1}, expected: 0}, // impossible
+		{counts: []int{2, 2}, expected: 2}, // ABAB, BABA
+		{counts: []int{2, 2, 2}, expected: 30},
+		{counts: []int{1, 1, 1}, expected: 6},
+	}
+
+	for _, tc := range tests {
+		if count := countCyclic(tc.counts); count.Int64() != tc.expected {
+			t.Errorf("%v — expected: %d, got: %s", tc.counts, tc.expected, count)
+		}
+	}
+}
+
+func TestAlternationSequenceProbability(t *testing.T) {
+	alternation := AnalyzeAlternation("AAXYYXBBXZZ", []groups.Group{
+		{Segments: []string{"AA", "BB"}},
+		{Segments: []string{"YY", "ZZ"}},
+	})
+
+	if alternation.FormatSequence() != "0,1,0,1" {
+		t.Errorf("sequence — expected: 0,1,0,1, got: %s", alternation.FormatSequence())
+	}
+
+	// one of the 6 orderings of AABB
+	if math.Abs(alternation.SequenceProbability-1.0/6) > 1e-9 {
+		t.Errorf("sequence probability — expected: %f, got: %f",
+			1.0/6,
+			alternation.SequenceProbability,
+		)
+	}
+}
+
+func TestAlternationMatches(t *testing.T) {
+	tests := []struct {
+		sequence []int
+		expected []AlternationPattern
+	}{
+		{[]int{0, 1, 0, 1, 0, 1}, []AlternationPattern{StrictAlternation, CyclicAlternation}},
+		{
+			[]int{0, 1, 0},
+			[]AlternationPattern{StrictAlternation, CyclicAlternation, PalindromicAlternation},
+		},
+		{[]int{0, 1, 2, 1, 0, 2}, []AlternationPattern{CyclicAlternation}},
+		{[]int{0, 1, 1, 0}, []AlternationPattern{PalindromicAlternation}},
+		{[]int{0, 0, 1, 1}, []AlternationPattern{BlockAlternation}},
+		{[]int{0, 0, 1, 0, 1, 1}, []AlternationPattern{NoAlternation}},
+	}
+
+	for _, tc := range tests {
+		alternation := Alternation{Sequence: tc.sequence}
+
+		var matched []AlternationPattern
+		for _, pattern := range alternationPatterns {
+			if alternation.Matches(pattern) {
+				matched = append(matched, pattern)
+			}
+		}
+
+		if !slices.Equal(matched, tc.expected) {
+			t.Errorf("%v — expected: %v, got: %v", tc.sequence, tc.expected, matched)
+		}
+	}
+
+	// the probability of a pattern includes the more specific ones:
+	// 2 cyclic orderings of AAABBB (ABABAB, BABABA), both strict
+	alternation := Alternation{Sequence: []int{0, 1, 0, 1, 0, 1}}
+	if p := alternation.PatternProbability(CyclicAlternation); math.Abs(p-2.0/20) > 1e-9 {
+		t.Errorf("cyclic probability — expected: %f, got: %f", 2.0/20, p)
+	}
+}
+
+func TestGroupsSequence(t *testing.T) {
+	type test struct {
+		name             string
+		ciphertext       string
+		groups           []groups.Group
+		expectedSequence []int
+	}
+
+	tests := []test{
+		{
+			name:       "located segments",
+			ciphertext: "AAXYYXBBXZZ",
+			groups: []groups.Group{
+				{
+					Segments: []string{"BB", "AA"},
+					Spans:    []groups.Span{{Start: 6, End: 8}, {Start: 0, End: 2}},
+				},
+				{
+					Segments: []string{"ZZ", "YY"},
+					Spans:    []groups.Span{{Start: 9, End: 11}, {Start: 3, End: 5}},
+				},
+			},
+			expectedSequence: []int{0, 1, 0, 1},
+		},
+		{
+			// only the locations tell identical segments apart
+			name:       "located identical segments",
+			ciphertext: "ABXABXCDXCD",
+			groups: []groups.Group{
+				{
+					Segments: []string{"AB", "CD"},
+					Spans:    []groups.Span{{Start: 0, End: 2}, {Start: 6, End: 8}},
+				},
+				{
+					Segments: []string{"AB", "CD"},
+					Spans:    []groups.Span{{Start: 3, End: 5}, {Start: 9, End: 11}},
+				},
+			},
+			expectedSequence: []int{0, 1, 0, 1},
+		},
+		{
+			// "AB" must not be matched at the beginning of "ABC"
+			name:       "segment prefix of another one",
+			ciphertext: "ABCXDEXABXFGH",
+			groups: []groups.Group{
+				{Segments: []string{"AB", "ABC"}},
+				{Segments: []string{"DE", "FGH"}},
+			},
+			expectedSequence: []int{0, 1, 0, 1},
+		},
+		{
+			name:       "repeated segments",
+			ciphertext: "ABXCDXABXCD",
+			groups: []groups.Group{
+				{Segments: []string{"AB", "AB"}},
+				{Segments: []string{"CD", "CD"}},
+			},
+			expectedSequence: []int{0, 1, 0, 1},
+		},
+		{
+			// fewer segments than groups
+			name:       "missing segments",
+			ciphertext: "ABXCD",
+			groups: []groups.Group{
+				{Segments: []string{"AB"}},
+				{Segments: []string{"EF"}},
+				{Segments: []string{"GH"}},
+			},
+			expectedSequence: []int{0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sequence := groupsSequence(tc.ciphertext, tc.groups)
+			if !slices.Equal(sequence, tc.expectedSequence) {
+				t.Errorf("expected: %v, got: %v", tc.expectedSequence, sequence)
+			}
+
+			// no panic, whatever the number of segments found
+			AnalyzeAlternation(tc.ciphertext, tc.groups)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+)
+
+const checkpointVersion = 1
+
+// Settings are the settings of a simulation
+type Settings struct {
+	// seed of the pseudo-K4s generator
+	// (nil: nondeterministic generator)
+	Seed *uint64 `json:"seed,omitempty"`
+
+	// generator of the pseudo-K4s
+	NullModel string `json:"null_model"`
+
+	// alphabet of the pseudo-K4s
+	// (empty: A–Z, for the simulations run before alphabets were introduced)
+	Alphabet string `json:"alphabet,omitempty"`
+
+	// length of the pseudo-K4s
+	CiphertextLength int `json:"ciphertext_length"`
+
+	// sets of letters acting as separators at once
+	Separators []string `json:"separators"`
+
+	// definitions of the user-defined metrics
+	// (e.g., "Long segments: min_segment_len=4")
+	Metrics []string `json:"metrics,omitempty"`
+
+	// names of the metrics met by K4 with the same alphabet and separators:
+	// the empirical p-value of the observation made on K4 is only reported
+	// for them (empty: none, or not recorded)
+	K4Metrics []string `json:"k4_metrics,omitempty"`
+}
+
+// Checkpoint is the machine-readable state of a simulation
+type Checkpoint struct {
+	Version  int      `json:"version"`
+	Settings Settings `json:"settings"`
+
+	// number of generated pseudo-K4s
+	// (the seeded generator resumes from the next simulation id)
+	SimulationsCount uint `json:"simulations_count"`
+
+	// number of collections of groups per metric
+	Collections map[string]uint `json:"collections"`
+
+	// number of pseudo-K4s with at least one collection of groups
+	// per metric
+	Simulations map[string]uint `json:"simulations"`
+}
+
+// AlphabetName returns the name of the alphabet of the pseudo-K4s
+func (s Settings) AlphabetName() string {
+	if s.Alphabet == "" {
+		return alphabets.Latin
+	}
+	return s.Alphabet
+}
+
+// GetMetrics returns the user-defined metrics
+func (s Settings) GetMetrics() ([]Metric, error) {
+	metrics := make([]Metric, len(s.Metrics))
+	for i, definition := range s.Metrics {
+		metric, err := ParseMetric(definition)
+		if err != nil {
+			return nil, err
+		}
+		metrics[i] = metric
+	}
+
+	return metrics, nil
+}
+
+// MetricNames returns the names of the metrics,
+// the default ones first
+func (s Settings) MetricNames() []string {
+	var names []string
+	for _, metric := range defaultMetrics {
+		names = append(names, metric.Name)
+	}
+
+	for _, definition := range s.Metrics {
+		name, _, _ := strings.Cut(definition, ":")
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	return names
+}
+
+// Compatible ensures that two simulations use the same settings
+// (the seeds are not compared)
+func (s Settings) Compatible(other Settings) error {
+	if s.NullModel != other.NullModel {
+		return fmt.Errorf("different null models: %q, %q",
+			s.NullModel,
+			other.NullModel,
+		)
+	}
+
+	if s.AlphabetName() != other.AlphabetName() {
+		return fmt.Errorf("different alphabets: %q, %q",
+			s.AlphabetName(),
+			other.AlphabetName(),
+		)
+	}
+
+	if s.CiphertextLength != other.CiphertextLength {
+		return fmt.Errorf("different ciphertext lengths: %d, %d",
+			s.CiphertextLength,
+			other.CiphertextLength,
+		)
+	}
+
+	if !slices.Equal(s.Separators, other.Separators) {
+		return fmt.Errorf("different separators: %v, %v",
+			s.Separators,
+			other.Separators,
+		)
+	}
+
+	if !slices.Equal(s.Metrics, other.Metrics) {
+		return fmt.Errorf("different metrics: %v, %v",
+			s.Metrics,
+			other.Metrics,
+		)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint reads a checkpoint file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(content, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	if checkpoint.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version: %d",
+			checkpoint.Version,
+		)
+	}
+
+	return &checkpoint, nil
+}
+
+// Write writes the checkpoint file atomically
+// (a crash while writing does not corrupt the previous checkpoint)
+func (c *Checkpoint) Write(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,142 @@
+package helpers
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	recorder := getTestRecorder(t)
+	recorder.SetSeed(42)
+	recorder.SetNullModel("uniform")
+	recorder.SetCiphertextLength(97)
+	recorder.SetSeparatorSets([][]rune{{'W'}, {'X', 'Y'}})
+	recorder.Update(10)
+	recorder.Record(3, "ABCDEFGHIJKL", []groups.Group{
+		{Segments: []string{"ABC", "GHI"}},
+		{Segments: []string{"DEF", "JKL"}},
+	})
+
+	if err := recorder.Snapshot().Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// resume the simulation
+	resumed := getTestRecorder(t)
+	if err := resumed.Restore(checkpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(resumed.Snapshot(), recorder.Snapshot()) {
+		t.Errorf("expected: %+v, got: %+v", recorder.Snapshot(), resumed.Snapshot())
+	}
+
+	if resumed.simulationsCount != 10 || resumed.collectionsWithMetric[k4LikeMetric] != 1 {
+		t.Errorf("unexpected counters: %d simulations, %d K4-like groups",
+			resumed.simulationsCount,
+			resumed.collectionsWithMetric[k4LikeMetric],
+		)
+	}
+
+	if *resumed.GetSettings().Seed != 42 {
+		t.Errorf("seed — expected: 42, got: %d", *resumed.GetSettings().Seed)
+	}
+}
+
+func TestRestoreMissingMetrics(t *testing.T) {
+	// checkpoint saved before the cyclic alternation metric was introduced
+	checkpoint := &Checkpoint{
+		Version:          checkpointVersion,
+		Settings:         Settings{NullModel: "uniform", CiphertextLength: 97},
+		SimulationsCount: 10,
+		Collections:      map[string]uint{"K4-like groups": 1},
+		Simulations:      map[string]uint{"K4-like groups": 1},
+	}
+
+	resumed := getTestRecorder(t)
+	if err := resumed.Restore(checkpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed.Record(11, "ABCABXCABCAB", []groups.Group{
+		{Segments: []string{"ABCAB"}},
+		{Segments: []string{"CABCAB"}},
+	})
+
+	snapshot := resumed.Snapshot()
+	if _, ok := snapshot.Collections["Cyclic alternation"]; ok {
+		t.Errorf("expected no cyclic alternation collections, got: %+v", snapshot.Collections)
+	}
+
+	if _, ok := snapshot.Simulations["Cyclic alternation"]; ok {
+		t.Errorf("expected no cyclic alternation pseudo-K4s, got: %+v", snapshot.Simulations)
+	}
+
+	// the metrics of the checkpoint are still counted
+	if snapshot.Collections["K4-like groups"] != 2 {
+		t.Errorf("K4-like groups — expected: 2, got: %d", snapshot.Collections["K4-like groups"])
+	}
+
+	if statistics := FormatStatistics(snapshot); strings.Contains(statistics, "Cyclic") {
+		t.Errorf("expected no cyclic alternation row: %s", statistics)
+	}
+}
+
+func TestSettingsCompatible(t *testing.T) {
+	settings := Settings{
+		NullModel:        "uniform",
+		CiphertextLength: 97,
+		Separators:       []string{"W"},
+	}
+
+	other := settings
+	if err := settings.Compatible(other); err != nil {
+		t.Errorf("expected compatible settings, got: %s", err)
+	}
+
+	other.NullModel = "shuffle"
+	if settings.Compatible(other) == nil {
+		t.Error("expected incompatible null models")
+	}
+
+	other = settings
+	other.CiphertextLength = 98
+	if settings.Compatible(other) == nil {
+		t.Error("expected incompatible ciphertext lengths")
+	}
+
+	other = settings
+	other.Metrics = []string{"Long segments: min_segment_len=4"}
+	if settings.Compatible(other) == nil {
+		t.Error("expected incompatible metrics")
+	}
+
+	other = settings
+	other.Separators = []string{"X"}
+	if settings.Compatible(other) == nil {
+		t.Error("expected incompatible separators")
+	}
+
+	// simulations run before alphabets were introduced used A–Z
+	other = settings
+	other.Alphabet = "latin"
+	if err := settings.Compatible(other); err != nil {
+		t.Errorf("expected compatible settings, got: %s", err)
+	}
+
+	other.Alphabet = "kryptos"
+	if settings.Compatible(other) == nil {
+		t.Error("expected incompatible alphabets")
+	}
+}
@@ -0,0 +1,29 @@
+package helpers
+
+import "math"
+
+// z-score of the 95% confidence intervals
+const z95 = 1.959964
+
+// WilsonInterval returns the 95% Wilson score interval of a proportion
+func WilsonInterval(count, totalCount uint) (float64, float64) {
+	if totalCount == 0 {
+		return 0, 1
+	}
+
+	n := float64(totalCount)
+	p := float64(count) / n
+	z2 := z95 * z95
+
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z95 / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// EmpiricalPValue returns the probability of observing a characteristic
+// at least once in a pseudo-K4, given that it has been observed in count
+// out of totalCount pseudo-K4s (the real observation being included)
+func EmpiricalPValue(count, totalCount uint) float64 {
+	return float64(count+1) / float64(totalCount+1)
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	type test struct {
+		name       string
+		count      uint
+		totalCount uint
+		low        float64
+		high       float64
+	}
+
+	tests := []test{
+		{name: "no simulation", count: 0, totalCount: 0, low: 0, high: 1},
+		{name: "half", count: 50, totalCount: 100, low: 0.4038, high: 0.5962},
+		{name: "none", count: 0, totalCount: 100, low: 0, high: 0.0370},
+		{name: "K4-like", count: 458, totalCount: 1031972, low: 0.000405, high: 0.000486},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			low, high := WilsonInterval(tc.count, tc.totalCount)
+
+			if math.Abs(low-tc.low) > 1e-4 || math.Abs(high-tc.high) > 1e-4 {
+				t.Errorf("expected: [%f, %f], got: [%f, %f]",
+					tc.low, tc.high,
+					low, high,
+				)
+			}
+		})
+	}
+}
+
+func TestEmpiricalPValue(t *testing.T) {
+	if p := EmpiricalPValue(0, 99); p != 0.01 {
+		t.Errorf("expected: 0.01, got: %f", p)
+	}
+
+	if p := EmpiricalPValue(99, 99); p != 1 {
+		t.Errorf("expected: 1, got: %f", p)
+	}
+}
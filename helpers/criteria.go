@@ -0,0 +1,306 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+// criteria keys
+const (
+	minSegmentLengthKey = "min_segment_len"
+	maxSegmentLengthKey = "max_segment_len"
+	groupsKey           = "groups"
+	minGroupsKey        = "min_groups"
+	maxGroupsKey        = "max_groups"
+	minGroupLengthKey   = "min_group_len"
+	alternationKey      = "alternation"
+	maxProbabilityKey   = "max_alternation_p"
+)
+
+// CriteriaKeys lists the keys of the criteria language
+var CriteriaKeys = []string{
+	minSegmentLengthKey,
+	maxSegmentLengthKey,
+	groupsKey,
+	minGroupsKey,
+	maxGroupsKey,
+	minGroupLengthKey,
+	alternationKey,
+	maxProbabilityKey,
+}
+
+var alternationPatterns = []AlternationPattern{
+	StrictAlternation,
+	CyclicAlternation,
+	PalindromicAlternation,
+	BlockAlternation,
+	NoAlternation,
+}
+
+// Criteria are the characteristics required from a collection of groups
+// with the same letter frequency distribution shapes to be counted by
+// a metric (0 or empty: no constraint)
+type Criteria struct {
+	// minimum and maximum number of symbols of each segment
+	MinSegmentLength int
+	MaxSegmentLength int
+
+	// number of groups (exact, minimum, and maximum)
+	Groups    int
+	MinGroups int
+	MaxGroups int
+
+	// minimum number of symbols of each group
+	MinGroupLength int
+
+	// alternation patterns accepted (e.g., strict or cyclic)
+	Alternation []AlternationPattern
+
+	// maximum probability of the alternation pattern
+	// if the segments were randomly ordered
+	MaxAlternationProbability float64
+}
+
+// ParseCriteria parses comma-separated criteria
+// (e.g., "min_segment_len=4, groups=2, alternation=strict|cyclic")
+func ParseCriteria(s string) (Criteria, error) {
+	var criteria Criteria
+
+	for _, criterion := range strings.Split(s, ",") {
+		criterion = strings.TrimSpace(criterion)
+		if criterion == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(criterion, "=")
+		if !found {
+			return Criteria{}, fmt.Errorf("invalid criterion %q: expected key=value", criterion)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if key == alternationKey {
+			for _, pattern := range strings.Split(value, "|") {
+				pattern := AlternationPattern(strings.TrimSpace(pattern))
+				if !slices.Contains(alternationPatterns, pattern) {
+					return Criteria{}, fmt.Errorf(
+						"invalid alternation pattern %q (available: strict, cyclic, "+
+							"palindromic, block, none)",
+						pattern,
+					)
+				}
+				criteria.Alternation = append(criteria.Alternation, pattern)
+			}
+			continue
+		}
+
+		if key == maxProbabilityKey {
+			probability, err := strconv.ParseFloat(value, 64)
+			if err != nil || probability <= 0 || probability > 1 {
+				return Criteria{}, fmt.Errorf(
+					"invalid criterion %q: expected a probability in (0, 1]",
+					criterion,
+				)
+			}
+			criteria.MaxAlternationProbability = probability
+			continue
+		}
+
+		var field *int
+		switch key {
+		case minSegmentLengthKey:
+			field = &criteria.MinSegmentLength
+		case maxSegmentLengthKey:
+			field = &criteria.MaxSegmentLength
+		case groupsKey:
+			field = &criteria.Groups
+		case minGroupsKey:
+			field = &criteria.MinGroups
+		case maxGroupsKey:
+			field = &criteria.MaxGroups
+		case minGroupLengthKey:
+			field = &criteria.MinGroupLength
+		default:
+			return Criteria{}, fmt.Errorf(
+				"unknown criterion %q (available: %s)",
+				key,
+				strings.Join(CriteriaKeys, ", "),
+			)
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil || number <= 0 {
+			return Criteria{}, fmt.Errorf(
+				"invalid criterion %q: expected a positive integer",
+				criterion,
+			)
+		}
+		*field = number
+	}
+
+	return criteria, nil
+}
+
+// String returns the canonical representation of the criteria
+func (c Criteria) String() string {
+	var criteria []string
+
+	for _, criterion := range []struct {
+		key   string
+		value int
+	}{
+		{minSegmentLengthKey, c.MinSegmentLength},
+		{maxSegmentLengthKey, c.MaxSegmentLength},
+		{groupsKey, c.Groups},
+		{minGroupsKey, c.MinGroups},
+		{maxGroupsKey, c.MaxGroups},
+		{minGroupLengthKey, c.MinGroupLength},
+	} {
+		if criterion.value > 0 {
+			criteria = append(criteria, fmt.Sprintf("%s=%d", criterion.key, criterion.value))
+		}
+	}
+
+	if len(c.Alternation) > 0 {
+		patterns := make([]string, len(c.Alternation))
+		for i, pattern := range c.Alternation {
+			patterns[i] = string(pattern)
+		}
+		criteria = append(criteria, alternationKey+"="+strings.Join(patterns, "|"))
+	}
+
+	if c.MaxAlternationProbability > 0 {
+		criteria = append(criteria, maxProbabilityKey+"="+
+			strconv.FormatFloat(c.MaxAlternationProbability, 'g', -1, 64),
+		)
+	}
+
+	return strings.Join(criteria, ", ")
+}
+
+// Match identifies whether a collection of groups meets the criteria
+func (c Criteria) Match(gs []groups.Group, classification Classification) bool {
+	groupsCount := len(gs)
+	if (c.Groups > 0 && groupsCount != c.Groups) ||
+		(c.MinGroups > 0 && groupsCount < c.MinGroups) ||
+		(c.MaxGroups > 0 && groupsCount > c.MaxGroups) {
+		return false
+	}
+
+	for _, group := range gs {
+		groupLength := 0
+
+		for _, segment := range group.Segments {
+			length := utf8.RuneCountInString(segment)
+			if (c.MinSegmentLength > 0 && length < c.MinSegmentLength) ||
+				(c.MaxSegmentLength > 0 && length > c.MaxSegmentLength) {
+				return false
+			}
+			groupLength += length
+		}
+
+		if c.MinGroupLength > 0 && groupLength < c.MinGroupLength {
+			return false
+		}
+	}
+
+	alternation := classification.Alternation
+	if len(c.Alternation) == 0 {
+		return c.MaxAlternationProbability == 0 ||
+			alternation.Probability <= c.MaxAlternationProbability
+	}
+
+	// the sequence follows an accepted pattern (not necessarily
+	// its most specific one), unlikely enough if required
+	for _, pattern := range c.Alternation {
+		if alternation.Matches(pattern) &&
+			(c.MaxAlternationProbability == 0 ||
+				alternation.PatternProbability(pattern) <= c.MaxAlternationProbability) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Metric counts the collections of groups meeting criteria
+type Metric struct {
+	Name     string
+	Criteria Criteria
+}
+
+// String returns the definition of the metric (e.g., "Long segments: min_segment_len=4")
+func (m Metric) String() string {
+	return strings.TrimSpace(m.Name + ": " + m.Criteria.String())
+}
+
+// ParseMetric parses the definition of a metric: its name,
+// followed by a colon and its criteria
+// (e.g., "Long segments: min_segment_len=4, alternation=strict")
+func ParseMetric(definition string) (Metric, error) {
+	name, criteria, found := strings.Cut(definition, ":")
+	if !found {
+		return Metric{}, fmt.Errorf("invalid metric %q: expected {{name}}: {{criteria}}", definition)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "\t\n") {
+		return Metric{}, fmt.Errorf("invalid metric name: %q", name)
+	}
+
+	parsed, err := ParseCriteria(criteria)
+	if err != nil {
+		return Metric{}, fmt.Errorf("metric %q: %w", name, err)
+	}
+
+	return Metric{Name: name, Criteria: parsed}, nil
+}
+
+// ParseMetrics reads definitions of metrics, one per line
+// (empty lines and lines starting with '#' are ignored)
+func ParseMetrics(r io.Reader) ([]Metric, error) {
+	var metrics []Metric
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		metric, err := ParseMetric(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// LoadMetrics reads a file of definitions of metrics
+func LoadMetrics(path string) ([]Metric, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	metrics, err := ParseMetrics(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return metrics, nil
+}
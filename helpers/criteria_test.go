@@ -0,0 +1,205 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestParseCriteria(t *testing.T) {
+	type test struct {
+		name           string
+		criteria       string
+		expectedString string
+		expectedError  bool
+	}
+
+	tests := []test{
+		{
+			name:           "no criteria",
+			criteria:       "",
+			expectedString: "",
+		},
+		{
+			name:           "canonical order",
+			criteria:       "min_group_len=30, alternation=strict, groups=2, min_segment_len=4",
+			expectedString: "min_segment_len=4, groups=2, min_group_len=30, alternation=strict",
+		},
+		{
+			name:           "several patterns",
+			criteria:       "alternation = strict|cyclic,max_alternation_p=0.05",
+			expectedString: "alternation=strict|cyclic, max_alternation_p=0.05",
+		},
+		{
+			name:          "unknown key",
+			criteria:      "min_len=3",
+			expectedError: true,
+		},
+		{
+			name:          "missing value",
+			criteria:      "groups",
+			expectedError: true,
+		},
+		{
+			name:          "invalid number",
+			criteria:      "groups=0",
+			expectedError: true,
+		},
+		{
+			name:          "invalid pattern",
+			criteria:      "alternation=zigzag",
+			expectedError: true,
+		},
+		{
+			name:          "invalid probability",
+			criteria:      "max_alternation_p=2",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			criteria, err := ParseCriteria(tc.criteria)
+			if tc.expectedError != (err != nil) {
+				t.Fatalf("error — expected: %t, got: %v", tc.expectedError, err)
+			}
+
+			if criteria.String() != tc.expectedString {
+				t.Errorf("expected: %q, got: %q", tc.expectedString, criteria.String())
+			}
+		})
+	}
+}
+
+func TestCriteriaMatch(t *testing.T) {
+	// K4 split by W: 2 strictly alternating groups of 46 letters
+	gs := []groups.Group{
+		{Segments: []string{"OBKRUOXOGHULBSOLIFBB", "TQSJQSSEKZZ", "INFBNYPVTTMZFPK"}},
+		{Segments: []string{"FLRVQQPRNGKSSOT", "ATJKLUDIA", "GDKZXTJCDIGKUHUAUEKCAR"}},
+	}
+	classification := Classify(
+		"OBKRUOXOGHULBSOLIFBBWFLRVQQPRNGKSSOTWTQSJQSSEKZZWATJKLUDIAW"+
+			"INFBNYPVTTMZFPKWGDKZXTJCDIGKUHUAUEKCAR",
+		gs,
+	)
+
+	type test struct {
+		criteria      string
+		expectedMatch bool
+	}
+
+	tests := []test{
+		{criteria: "", expectedMatch: true},
+		{criteria: "min_segment_len=4, groups=2, alternation=strict, min_group_len=30", expectedMatch: true},
+		{criteria: "min_segment_len=10", expectedMatch: false},
+		{criteria: "max_segment_len=20", expectedMatch: false},
+		{criteria: "groups=3", expectedMatch: false},
+		{criteria: "min_groups=2, max_groups=2", expectedMatch: true},
+		{criteria: "min_group_len=47", expectedMatch: false},
+		// a strictly alternating sequence of several groups is cyclic as well
+		{criteria: "alternation=cyclic", expectedMatch: true},
+		{criteria: "alternation=cyclic|block", expectedMatch: true},
+		{criteria: "alternation=palindromic|block", expectedMatch: false},
+		{criteria: "alternation=none", expectedMatch: false},
+		{criteria: "alternation=cyclic, max_alternation_p=0.1", expectedMatch: true},
+		{criteria: "alternation=cyclic, max_alternation_p=0.05", expectedMatch: false},
+		{criteria: "max_alternation_p=0.1", expectedMatch: true},
+		{criteria: "max_alternation_p=0.05", expectedMatch: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.criteria, func(t *testing.T) {
+			criteria, err := ParseCriteria(tc.criteria)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if match := criteria.Match(gs, classification); match != tc.expectedMatch {
+				t.Errorf("expected: %t, got: %t", tc.expectedMatch, match)
+			}
+		})
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	metrics, err := ParseMetrics(strings.NewReader(
+		"# hypotheses\n" +
+			"\n" +
+			"Long segments: min_segment_len=4\n" +
+			"All:\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metrics) != 2 ||
+		metrics[0].String() != "Long segments: min_segment_len=4" ||
+		metrics[1].String() != "All:" {
+		t.Errorf("unexpected metrics: %v", metrics)
+	}
+
+	for _, definition := range []string{"min_segment_len=4", ": groups=2", "Long: groups=A"} {
+		if _, err := ParseMetrics(strings.NewReader(definition)); err == nil {
+			t.Errorf("%q — expected an error", definition)
+		}
+	}
+}
+
+func TestRecordUserDefinedMetrics(t *testing.T) {
+	recorder := getTestRecorder(t)
+	recorder.Update(2)
+
+	long, _ := ParseMetric("Long segments: min_segment_len=4")
+	two, _ := ParseMetric("Two groups: groups=2, alternation=strict")
+	if err := recorder.SetMetrics([]Metric{long, two}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.Record(1, "ABCDEFGHIJKL", []groups.Group{
+		{Segments: []string{"ABC", "GHI"}},
+		{Segments: []string{"DEF", "JKL"}},
+	})
+	recorder.Record(2, "ABCDXEFGHXIJKLXMNOP", []groups.Group{
+		{Segments: []string{"ABCD", "IJKL"}},
+		{Segments: []string{"EFGH", "MNOP"}},
+	})
+
+	snapshot := recorder.Snapshot()
+	if snapshot.Collections["Long segments"] != 1 ||
+		snapshot.Collections["Two groups"] != 2 ||
+		snapshot.Simulations["Two groups"] != 2 {
+		t.Errorf("unexpected statistics: %+v", snapshot)
+	}
+
+	// each metric is written as its own row
+	statistics := FormatStatistics(snapshot)
+	for _, row := range []string{"Long segments", "Two groups"} {
+		if strings.Count(statistics, "\n"+row) != 2 {
+			t.Errorf("expected the rows of %q: %s", row, statistics)
+		}
+	}
+
+	// the metrics are saved along with the settings
+	parsed, err := ParseStatistics(strings.NewReader(statistics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed.Settings.Metrics) != 2 ||
+		parsed.Settings.Metrics[1] != "Two groups: groups=2, alternation=strict" ||
+		parsed.Collections["Two groups"] != 2 {
+		t.Errorf("unexpected statistics: %+v", parsed)
+	}
+
+	// names must be unique
+	for _, name := range []string{"K4-like groups", "Separators"} {
+		if err := recorder.SetMetrics([]Metric{{Name: name}}); err == nil {
+			t.Errorf("%q — expected an error", name)
+		}
+	}
+
+	if err := recorder.SetMetrics([]Metric{long, long}); err == nil {
+		t.Error("expected an error for duplicate names")
+	}
+}
@@ -1,61 +1,117 @@
 package helpers
 
 import (
-	"crypto/rand"
-	"math/big"
+	"slices"
 	"strings"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/groups"
 )
 
-// Split splits the ciphertext based on a separator
+// Split splits the ciphertext based on one or several separators
 // and returns non-empty segments
-func Split(ciphertext string, separator rune) []string {
+func Split(ciphertext string, separators ...rune) []string {
 	return strings.FieldsFunc(ciphertext, func(s rune) bool {
-		return s == separator
+		return slices.Contains(separators, s)
 	})
 }
 
-// GenerateRandomString generates pseudo-K4s
-func GenerateRandomString(size int) string {
-	charSet := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+// SplitSegments splits the ciphertext based on one or several separators
+// and returns non-empty segments along with their location
+// (offsets in symbols)
+func SplitSegments(ciphertext string, separators ...rune) []groups.Segment {
+	var (
+		segments []groups.Segment
+		current  []rune
+		start    int
+	)
 
-	var sb strings.Builder
-	sb.Grow(size)
+	end := func(offset int) {
+		if len(current) > 0 {
+			segments = append(segments, groups.Segment{
+				Text: string(current),
+				Span: groups.Span{Start: start, End: offset},
+			})
+			current = current[:0]
+		}
+		start = offset + 1
+	}
 
-	for i := 0; i < size; i++ {
-		randomIndex, err := rand.Int(
-			rand.Reader,
-			big.NewInt(int64(len(charSet))),
-		)
-		if err != nil {
-			panic(err)
+	offset := 0
+	for _, c := range ciphertext {
+		if slices.Contains(separators, c) {
+			end(offset)
+		} else {
+			current = append(current, c)
 		}
-		sb.WriteByte(charSet[randomIndex.Int64()])
+		offset++
 	}
+	end(offset)
 
-	return sb.String()
+	return segments
+}
+
+// Combinations returns the combinations of size letters of a charset,
+// in lexicographic order
+// (example: "ABC" and size 2: "AB", "AC", "BC")
+func Combinations(charSet []rune, size int) [][]rune {
+	var (
+		combinations [][]rune
+		combine      func(start int, current []rune)
+	)
+
+	if size <= 0 || size > len(charSet) {
+		return nil
+	}
+
+	combine = func(start int, current []rune) {
+		if len(current) == size {
+			combinations = append(combinations, slices.Clone(current))
+			return
+		}
+
+		for i := start; i < len(charSet); i++ {
+			combine(i+1, append(current, charSet[i]))
+		}
+	}
+
+	combine(0, make([]rune, 0, size))
+
+	return combinations
 }
 
-func permute(input []string, start int, ch chan []string) {
-	if start == len(input)-1 {
-		temp := make([]string, len(input))
-		copy(temp, input)
-		ch <- temp
-		return
+// CountCombinations returns the number of combinations of size letters
+// of a charset of n letters, without generating them
+// (the count saturates at the maximum value of a uint)
+func CountCombinations(n, size int) uint {
+	if size < 0 || size > n {
+		return 0
 	}
 
-	for i := start; i < len(input); i++ {
-		input[start], input[i] = input[i], input[start]
-		permute(input, start+1, ch)
-		input[start], input[i] = input[i], input[start]
+	size = min(size, n-size)
+
+	count := uint(1)
+	for i := 1; i <= size; i++ {
+		// count * (n-size+i) / i is always an integer
+		next := count * uint(n-size+i)
+		if next/uint(n-size+i) != count {
+			return ^uint(0)
+		}
+		count = next / uint(i)
 	}
+
+	return count
 }
 
-// GeneratePermutations generates permutations of text segments
-func GeneratePermutations(input []string) <-chan []string {
-	ch := make(chan []string)
-	go func() {
-		defer close(ch)
-		permute(input, 0, ch)
-	}()
-	return ch
+// GenerateRandomString generates pseudo-K4s
+// (symbols drawn uniformly from an alphabet)
+func GenerateRandomString(source RandomSource, alphabet alphabets.Alphabet, size int) string {
+	var sb strings.Builder
+	sb.Grow(size)
+
+	for i := 0; i < size; i++ {
+		sb.WriteRune(alphabet.Symbol(source.IntN(alphabet.Len())))
+	}
+
+	return sb.String()
 }
@@ -2,8 +2,11 @@ package helpers
 
 import (
 	"reflect"
-	"sort"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/groups"
 )
 
 func TestSplit(t *testing.T) {
@@ -61,84 +64,162 @@ func TestSplit(t *testing.T) {
 	}
 }
 
-func sort2DSlice(slice [][]string) {
-	for _, s := range slice {
-		sort.Strings(s)
-	}
+func TestSplitSeveralSeparators(t *testing.T) {
+	output := Split("ABCXDEFYGHIXYJKL", 'X', 'Y')
+	expectedOutput := []string{"ABC", "DEF", "GHI", "JKL"}
 
-	sort.Slice(slice, func(i, j int) bool {
-		return slice[i][0] < slice[j][0]
-	})
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("expected: %v, got %v", expectedOutput, output)
+	}
 }
 
-func TestPermutations(t *testing.T) {
+func TestSplitSegments(t *testing.T) {
 	type test struct {
-		name                 string
-		input                []string
-		expectedPermutations [][]string
+		name           string
+		input          string
+		separators     []rune
+		expectedOutput []groups.Segment
 	}
 
 	tests := []test{
 		{
-			name:  "positive test 1",
-			input: []string{"A", "B"},
-			expectedPermutations: [][]string{
-				{
-					"A", "B",
-				},
-				{
-					"B", "A",
-				},
+			name:       "one separator",
+			input:      "ABCXDEFXGHI",
+			separators: []rune("X"),
+			expectedOutput: []groups.Segment{
+				{Text: "ABC", Span: groups.Span{Start: 0, End: 3}},
+				{Text: "DEF", Span: groups.Span{Start: 4, End: 7}},
+				{Text: "GHI", Span: groups.Span{Start: 8, End: 11}},
+			},
+		},
+		{
+			name:       "several separators, leading and trailing",
+			input:      "XABYYCDX",
+			separators: []rune("XY"),
+			expectedOutput: []groups.Segment{
+				{Text: "AB", Span: groups.Span{Start: 1, End: 3}},
+				{Text: "CD", Span: groups.Span{Start: 5, End: 7}},
 			},
 		},
 		{
-			name:  "positive test 2",
-			input: []string{"ABC", "DEF", "GHI"},
-			expectedPermutations: [][]string{
-				{
-					"ABC", "DEF", "GHI",
-				},
-				{
-					"ABC", "GHI", "DEF",
-				},
-				{
-					"DEF", "ABC", "GHI",
-				},
-				{
-					"DEF", "GHI", "ABC",
-				},
-				{
-					"GHI", "ABC", "DEF",
-				},
-				{
-					"GHI", "DEF", "ABC",
-				},
+			// offsets in symbols, not bytes
+			name:       "multibyte symbols",
+			input:      "△○◇☐⊕",
+			separators: []rune("◇"),
+			expectedOutput: []groups.Segment{
+				{Text: "△○", Span: groups.Span{Start: 0, End: 2}},
+				{Text: "☐⊕", Span: groups.Span{Start: 3, End: 5}},
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			var allPermutations [][]string
+			output := SplitSegments(tc.input, tc.separators...)
+			if !reflect.DeepEqual(output, tc.expectedOutput) {
+				t.Errorf("expected: %v, got %v", tc.expectedOutput, output)
+			}
 
-			for permutation := range GeneratePermutations(
-				tc.input,
-			) {
-				allPermutations = append(allPermutations, permutation)
+			// the same segments as Split
+			texts := make([]string, len(output))
+			for i, segment := range output {
+				texts[i] = segment.Text
 			}
 
-			sort2DSlice(tc.expectedPermutations)
-			sort2DSlice(allPermutations)
+			if !reflect.DeepEqual(texts, Split(tc.input, tc.separators...)) {
+				t.Errorf("expected the segments of Split, got %v", texts)
+			}
+		})
+	}
+}
 
-			if !reflect.DeepEqual(
-				tc.expectedPermutations,
-				allPermutations,
-			) {
-				t.Errorf("expected: %v, got %v",
-					tc.expectedPermutations,
-					allPermutations,
-				)
+func TestCombinations(t *testing.T) {
+	output := Combinations([]rune("ABCD"), 2)
+	expectedOutput := [][]rune{
+		[]rune("AB"), []rune("AC"), []rune("AD"),
+		[]rune("BC"), []rune("BD"), []rune("CD"),
+	}
+
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("expected: %q, got %q", expectedOutput, output)
+	}
+
+	if Combinations([]rune("ABC"), 4) != nil {
+		t.Error("expected no combination")
+	}
+}
+
+func TestCountCombinations(t *testing.T) {
+	testCases := []struct {
+		n, size  int
+		expected uint
+	}{
+		{4, 2, 6},
+		{26, 1, 26},
+		{26, 3, 2600},
+		{26, 13, 10400600},
+		{3, 4, 0},
+		{1000, 500, ^uint(0)},
+	}
+
+	for _, tc := range testCases {
+		if count := CountCombinations(tc.n, tc.size); count != tc.expected {
+			t.Errorf("C(%d, %d): expected %d, got %d", tc.n, tc.size, tc.expected, count)
+		}
+	}
+
+	if count := CountCombinations(4, 2); count != uint(len(Combinations([]rune("ABCD"), 2))) {
+		t.Errorf("expected the number of combinations, got %d", count)
+	}
+}
+
+func TestGenerateRandomString(t *testing.T) {
+	for _, source := range []RandomSource{
+		GetCryptoSource(),
+		GetSeededSource(42, 1),
+	} {
+		s := GenerateRandomString(source, alphabets.GetLatin(), 97)
+		if len(s) != 97 {
+			t.Errorf("length — expected: 97, got: %d", len(s))
+		}
+
+		for _, c := range s {
+			if c < 'A' || c > 'Z' {
+				t.Errorf("unexpected character: %q", c)
+			}
+		}
+	}
+}
+
+func TestGenerateRandomStringAlphabets(t *testing.T) {
+	digits, _ := alphabets.Get(alphabets.Digits)
+	symbols, _ := alphabets.New("△○◇☐⊕")
+
+	for _, alphabet := range []alphabets.Alphabet{digits, symbols} {
+		t.Run(alphabet.Name(), func(t *testing.T) {
+			s := GenerateRandomString(GetSeededSource(42, 1), alphabet, 97)
+			if length := utf8.RuneCountInString(s); length != 97 {
+				t.Errorf("length — expected: 97, got: %d", length)
+			}
+
+			if err := alphabet.Validate(s); err != nil {
+				t.Error(err)
 			}
 		})
 	}
 }
+
+func TestSeededSource(t *testing.T) {
+	// the same seed and simulation id generate the same pseudo-K4
+	a := GenerateRandomString(GetSeededSource(42, 7), alphabets.GetLatin(), 97)
+	b := GenerateRandomString(GetSeededSource(42, 7), alphabets.GetLatin(), 97)
+	if a != b {
+		t.Errorf("expected identical strings: %s, %s", a, b)
+	}
+
+	// different simulations generate different pseudo-K4s
+	c := GenerateRandomString(GetSeededSource(42, 8), alphabets.GetLatin(), 97)
+	if a == c {
+		t.Errorf("expected different strings: %s, %s", a, c)
+	}
+}
@@ -0,0 +1,216 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// counts of the metrics (e.g., "458/1031972")
+var countsRegexp = regexp.MustCompile(`^\s*(\d+)/(\d+)\s*$`)
+
+// ParseStatistics reads the statistics written into a statistics file
+// (files written before the settings and the pseudo-K4s metrics were
+// introduced are supported as well)
+func ParseStatistics(r io.Reader) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{
+		Version:     checkpointVersion,
+		Collections: make(map[string]uint),
+	}
+
+	// metrics counting collections of groups, then pseudo-K4s
+	metrics := checkpoint.Collections
+	totalCountFound := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == statisticsHeader {
+			checkpoint.Simulations = make(map[string]uint)
+			metrics = checkpoint.Simulations
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		name := strings.TrimSpace(fields[0])
+		value := ""
+		if len(fields) > 1 {
+			value = strings.TrimSpace(fields[1])
+		}
+
+		switch name {
+		case "Seed":
+			if value != "none" {
+				seed, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid seed: %q", value)
+				}
+				checkpoint.Settings.Seed = &seed
+			}
+			continue
+		case "Null model":
+			checkpoint.Settings.NullModel = value
+			continue
+		case "Alphabet":
+			checkpoint.Settings.Alphabet = value
+			continue
+		case "Ciphertext length":
+			length, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ciphertext length: %q", value)
+			}
+			checkpoint.Settings.CiphertextLength = length
+			continue
+		case "Separators":
+			if value != "" {
+				checkpoint.Settings.Separators = strings.Split(value, ",")
+			}
+			continue
+		case "Metric":
+			checkpoint.Settings.Metrics = append(checkpoint.Settings.Metrics, value)
+			continue
+		case "K4 metric":
+			checkpoint.Settings.K4Metrics = append(checkpoint.Settings.K4Metrics, value)
+			continue
+		}
+
+		// metric: find its counts
+		found := false
+		for _, field := range fields[1:] {
+			matches := countsRegexp.FindStringSubmatch(field)
+			if matches == nil {
+				continue
+			}
+
+			count, _ := strconv.ParseUint(matches[1], 10, 0)
+			totalCount, _ := strconv.ParseUint(matches[2], 10, 0)
+
+			if totalCountFound && uint(totalCount) != checkpoint.SimulationsCount {
+				return nil, fmt.Errorf("inconsistent number of simulations: %q", line)
+			}
+
+			metrics[name] = uint(count)
+			checkpoint.SimulationsCount = uint(totalCount)
+			totalCountFound = true
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("invalid statistics: %q", line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !totalCountFound {
+		return nil, errors.New("no statistics found")
+	}
+
+	return checkpoint, nil
+}
+
+// LoadStatistics reads a statistics file or a checkpoint file
+func LoadStatistics(path string) (*Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		return LoadCheckpoint(path)
+	}
+
+	checkpoint, err := ParseStatistics(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return checkpoint, nil
+}
+
+// MergeStatistics combines the statistics of several simulations
+// that used compatible settings
+func MergeStatistics(checkpoints []*Checkpoint) (*Checkpoint, error) {
+	if len(checkpoints) == 0 {
+		return nil, errors.New("no statistics to merge")
+	}
+
+	merged := &Checkpoint{
+		Version:     checkpointVersion,
+		Settings:    checkpoints[0].Settings,
+		Collections: make(map[string]uint),
+		Simulations: make(map[string]uint),
+	}
+
+	// the merged simulations do not share a seed
+	merged.Settings.Seed = nil
+
+	seeds := make(map[uint64]bool)
+
+	for i, checkpoint := range checkpoints {
+		if err := checkpoints[0].Settings.Compatible(checkpoint.Settings); err != nil {
+			return nil, fmt.Errorf("statistics #%d: %w", i+1, err)
+		}
+
+		// simulations using the same seed generate the same pseudo-K4s
+		if seed := checkpoint.Settings.Seed; seed != nil {
+			if seeds[*seed] {
+				return nil, fmt.Errorf(
+					"statistics #%d: seed %d already used (duplicate pseudo-K4s)",
+					i+1,
+					*seed,
+				)
+			}
+			seeds[*seed] = true
+		}
+
+		merged.SimulationsCount += checkpoint.SimulationsCount
+
+		for name, count := range checkpoint.Collections {
+			merged.Collections[name] += count
+		}
+
+		// the pseudo-K4s metrics are only merged if all the simulations
+		// recorded them
+		if checkpoint.Simulations == nil {
+			merged.Simulations = nil
+		} else if merged.Simulations != nil {
+			for name, count := range checkpoint.Simulations {
+				merged.Simulations[name] += count
+			}
+		}
+	}
+
+	// metrics missing from some statistics (i.e., recorded before
+	// the metric was introduced) cannot be merged
+	for _, checkpoint := range checkpoints {
+		dropMissingMetrics(merged.Collections, checkpoint.Collections)
+		if merged.Simulations != nil {
+			dropMissingMetrics(merged.Simulations, checkpoint.Simulations)
+		}
+	}
+
+	return merged, nil
+}
+
+// dropMissingMetrics removes the merged metrics missing from some statistics
+func dropMissingMetrics(merged, metrics map[string]uint) {
+	for name := range merged {
+		if _, ok := metrics[name]; !ok {
+			delete(merged, name)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getTestCheckpoint(seed uint64, simulationsCount uint) *Checkpoint {
+	return &Checkpoint{
+		Version: checkpointVersion,
+		Settings: Settings{
+			Seed:             &seed,
+			NullModel:        "uniform",
+			CiphertextLength: 97,
+			Separators:       []string{"A", "B"},
+		},
+		SimulationsCount: simulationsCount,
+		Collections: map[string]uint{
+			"Same distribution shapes": 20,
+			"Groups length > 2":        10,
+			"Alternating groups":       5,
+			"K4-like groups":           2,
+		},
+		Simulations: map[string]uint{
+			"Same distribution shapes": 15,
+			"Groups length > 2":        8,
+			"Alternating groups":       4,
+			"K4-like groups":           2,
+		},
+	}
+}
+
+func TestParseStatistics(t *testing.T) {
+	checkpoint := getTestCheckpoint(42, 1000)
+
+	parsed, err := ParseStatistics(strings.NewReader(FormatStatistics(checkpoint)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed, checkpoint) {
+		t.Errorf("expected: %+v, got: %+v", checkpoint, parsed)
+	}
+
+	checkpoint.Settings.Alphabet = "kryptos"
+
+	parsed, err = ParseStatistics(strings.NewReader(FormatStatistics(checkpoint)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Settings.Alphabet != "kryptos" {
+		t.Errorf("alphabet — expected: kryptos, got: %s", parsed.Settings.Alphabet)
+	}
+}
+
+func TestParseLegacyStatistics(t *testing.T) {
+	statistics := "Same distribution shapes \t1.74%\t     17961/1031972\n" +
+		"Groups length > 2        \t0.57%\t      5923/1031972\n" +
+		"Alternating groups       \t0.07%\t       671/1031972\n" +
+		"K4-like groups           \t0.04%\t       458/1031972\n"
+
+	parsed, err := ParseStatistics(strings.NewReader(statistics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.SimulationsCount != 1031972 || parsed.Collections["K4-like groups"] != 458 {
+		t.Errorf("unexpected statistics: %+v", parsed)
+	}
+
+	if parsed.Simulations != nil {
+		t.Errorf("expected no pseudo-K4s metrics, got: %v", parsed.Simulations)
+	}
+
+	if _, err := ParseStatistics(strings.NewReader("K4-like groups\t0.04%\n")); err == nil {
+		t.Error("expected an error for invalid statistics")
+	}
+}
+
+func TestMergeStatistics(t *testing.T) {
+	merged, err := MergeStatistics([]*Checkpoint{
+		getTestCheckpoint(1, 1000),
+		getTestCheckpoint(2, 3000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if merged.SimulationsCount != 4000 ||
+		merged.Collections["K4-like groups"] != 4 ||
+		merged.Simulations["Same distribution shapes"] != 30 {
+		t.Errorf("unexpected statistics: %+v", merged)
+	}
+
+	// metrics recorded by some statistics only are not merged
+	recent := getTestCheckpoint(3, 1000)
+	recent.Collections["Cyclic alternation"] = 3
+	recent.Simulations["Cyclic alternation"] = 2
+
+	merged, err = MergeStatistics([]*Checkpoint{getTestCheckpoint(1, 1000), recent})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := merged.Collections["Cyclic alternation"]; ok {
+		t.Errorf("unexpected partial metric: %+v", merged.Collections)
+	}
+
+	if _, ok := merged.Simulations["Cyclic alternation"]; ok {
+		t.Errorf("unexpected partial metric: %+v", merged.Simulations)
+	}
+
+	// same seed
+	if _, err := MergeStatistics([]*Checkpoint{
+		getTestCheckpoint(1, 1000),
+		getTestCheckpoint(1, 1000),
+	}); err == nil {
+		t.Error("expected an error for duplicate seeds")
+	}
+
+	// incompatible settings
+	incompatible := getTestCheckpoint(2, 1000)
+	incompatible.Settings.NullModel = "shuffle"
+	if _, err := MergeStatistics([]*Checkpoint{
+		getTestCheckpoint(1, 1000),
+		incompatible,
+	}); err == nil {
+		t.Error("expected an error for incompatible settings")
+	}
+}
@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+)
+
+// NormalizeOptions are the options of the normalization of a ciphertext
+type NormalizeOptions struct {
+	// drop the invalid characters instead of rejecting the ciphertext
+	// (they are reported in Normalized.Dropped)
+	Lenient bool
+
+	// keep the position of each symbol in the original text
+	KeepPositions bool
+
+	// alphabet of the ciphertext (default: A–Z)
+	Alphabet alphabets.Alphabet
+}
+
+// Position is the position of a character in the original text
+// (lines and columns start at 1)
+type Position struct {
+	// offset in runes
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// InvalidCharacter is a character that cannot be part of a ciphertext
+type InvalidCharacter struct {
+	Character rune
+	Position  Position
+}
+
+func (c InvalidCharacter) Error() string {
+	return fmt.Sprintf("invalid character %q at %s: not in the alphabet",
+		c.Character,
+		c.Position,
+	)
+}
+
+// Normalized is a ciphertext ready to be analyzed
+type Normalized struct {
+	Ciphertext string
+
+	// position in the original text of each symbol of the ciphertext
+	// (NormalizeOptions.KeepPositions only)
+	Positions []Position
+
+	// invalid characters dropped (NormalizeOptions.Lenient only)
+	Dropped []InvalidCharacter
+}
+
+var ErrNoSymbols = errors.New("no symbols found")
+
+// isIgnored identifies the characters that are not part of
+// a transcription: whitespace, line breaks, and the '?' characters
+// of the Kryptos sculpture (unless '?' is a symbol of the alphabet)
+func isIgnored(c rune, alphabet alphabets.Alphabet) bool {
+	return unicode.IsSpace(c) || (c == '?' && !alphabet.Contains(c))
+}
+
+// Normalize converts a transcription (e.g., Kryptos as laid out on the
+// sculpture) into a ciphertext written in the symbols of its alphabet
+// (e.g., uppercase letters): whitespace, line breaks, and '?' characters
+// are stripped, and the other characters are rejected (or dropped in
+// lenient mode)
+func Normalize(text string, options NormalizeOptions) (Normalized, error) {
+	var (
+		normalized Normalized
+		sb         strings.Builder
+	)
+
+	position := Position{Line: 1, Column: 1}
+
+	for _, c := range text {
+		current := position
+
+		position.Offset++
+		position.Column++
+		if c == '\n' {
+			position.Line++
+			position.Column = 1
+		}
+
+		if isIgnored(c, options.Alphabet) {
+			continue
+		}
+
+		symbol, ok := options.Alphabet.Normalize(c)
+		if !ok {
+			invalid := InvalidCharacter{Character: c, Position: current}
+			if !options.Lenient {
+				return Normalized{}, invalid
+			}
+
+			normalized.Dropped = append(normalized.Dropped, invalid)
+			continue
+		}
+
+		sb.WriteRune(symbol)
+		if options.KeepPositions {
+			normalized.Positions = append(normalized.Positions, current)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return Normalized{}, ErrNoSymbols
+	}
+
+	normalized.Ciphertext = sb.String()
+
+	return normalized, nil
+}
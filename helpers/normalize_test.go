@@ -0,0 +1,126 @@
+package helpers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+)
+
+func TestNormalize(t *testing.T) {
+	type test struct {
+		name               string
+		input              string
+		lenient            bool
+		alphabet           alphabets.Alphabet
+		expectedCiphertext string
+		expectedDropped    int
+		expectedError      error
+	}
+
+	latin25, _ := alphabets.Get(alphabets.Latin25)
+	symbols, _ := alphabets.New("△○◇?")
+
+	tests := []test{
+		{
+			name:               "uppercase letters",
+			input:              "OBKRUOXOGHULBSOLIFBB",
+			expectedCiphertext: "OBKRUOXOGHULBSOLIFBB",
+		},
+		{
+			name:               "lowercase letters",
+			input:              "obkrUOXOGH",
+			expectedCiphertext: "OBKRUOXOGH",
+		},
+		{
+			name:               "whitespace, line breaks, and '?'",
+			input:              " ?OBKR\r\n\tUOXOGHULBSO LIFBB?\n",
+			expectedCiphertext: "OBKRUOXOGHULBSOLIFBB",
+		},
+		{
+			name:  "invalid character",
+			input: "OBKR\nUO.XOGH",
+			expectedError: InvalidCharacter{
+				Character: '.',
+				Position:  Position{Offset: 7, Line: 2, Column: 3},
+			},
+		},
+		{
+			name:               "invalid characters, lenient mode",
+			input:              "OBKR-UOXOGH 42 É",
+			lenient:            true,
+			expectedCiphertext: "OBKRUOXOGH",
+			expectedDropped:    4,
+		},
+		{
+			name:               "I/J-merged alphabet",
+			input:              "jumbo JET",
+			alphabet:           latin25,
+			expectedCiphertext: "IUMBOIET",
+		},
+		{
+			name:               "symbols, including '?'",
+			input:              "△?○\n◇ △",
+			alphabet:           symbols,
+			expectedCiphertext: "△?○◇△",
+		},
+		{
+			name:     "symbols, letter",
+			input:    "△A○",
+			alphabet: symbols,
+			expectedError: InvalidCharacter{
+				Character: 'A',
+				Position:  Position{Offset: 1, Line: 1, Column: 2},
+			},
+		},
+		{
+			name:          "no symbols",
+			input:         " ? \n",
+			expectedError: ErrNoSymbols,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, err := Normalize(tc.input, NormalizeOptions{
+				Lenient:  tc.lenient,
+				Alphabet: tc.alphabet,
+			})
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("error — expected: %v, got: %v", tc.expectedError, err)
+			}
+
+			if normalized.Ciphertext != tc.expectedCiphertext {
+				t.Errorf("ciphertext — expected: %s, got: %s",
+					tc.expectedCiphertext,
+					normalized.Ciphertext,
+				)
+			}
+
+			if len(normalized.Dropped) != tc.expectedDropped {
+				t.Errorf("dropped — expected: %d, got: %d",
+					tc.expectedDropped,
+					len(normalized.Dropped),
+				)
+			}
+		})
+	}
+}
+
+func TestNormalizePositions(t *testing.T) {
+	normalized, err := Normalize("AB?\n C", NormalizeOptions{KeepPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Position{
+		{Offset: 0, Line: 1, Column: 1},
+		{Offset: 1, Line: 1, Column: 2},
+		{Offset: 5, Line: 2, Column: 2},
+	}
+
+	if !reflect.DeepEqual(normalized.Positions, expected) {
+		t.Errorf("expected: %v, got: %v", expected, normalized.Positions)
+	}
+}
@@ -0,0 +1,81 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OpenMetricsContentType is the media type of the OpenMetrics text format
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metrics prefix
+const openMetricsNamespace = "k4nundrum"
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// openMetricsFamily writes the metadata of a metric family
+func openMetricsFamily(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# TYPE %s_%s %s\n", openMetricsNamespace, name, metricType)
+	fmt.Fprintf(b, "# HELP %s_%s %s\n", openMetricsNamespace, name, help)
+}
+
+// openMetricsSample writes a sample, labeled by metric name if any
+func openMetricsSample(b *strings.Builder, name, metric string, value float64) {
+	b.WriteString(openMetricsNamespace + "_" + name)
+	if metric != "" {
+		fmt.Fprintf(b, `{metric="%s"}`, labelReplacer.Replace(metric))
+	}
+	b.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+// FormatOpenMetrics returns the counters and gauges of a simulation
+// in the OpenMetrics text format (e.g., for Prometheus)
+func FormatOpenMetrics(p Progress) string {
+	var b strings.Builder
+
+	statistics := p.Statistics
+	names := statistics.Settings.MetricNames()
+
+	openMetricsFamily(&b, "simulations", "counter", "Pseudo-K4s generated.")
+	openMetricsSample(&b, "simulations_total", "", float64(statistics.SimulationsCount))
+
+	openMetricsFamily(&b, "collections", "counter",
+		"Collections of groups with the same letter frequency distribution shapes, per metric.",
+	)
+	for _, name := range names {
+		if count, ok := statistics.Collections[name]; ok {
+			openMetricsSample(&b, "collections_total", name, float64(count))
+		}
+	}
+
+	openMetricsFamily(&b, "matching_simulations", "counter",
+		"Pseudo-K4s with at least one collection of groups, per metric.",
+	)
+	for _, name := range names {
+		if count, ok := statistics.Simulations[name]; ok {
+			openMetricsSample(&b, "matching_simulations_total", name, float64(count))
+		}
+	}
+
+	for _, gauge := range []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"target_simulations", "Pseudo-K4s after which the simulation stops (0: no target).", float64(p.Target)},
+		{"simulations_per_second", "Pseudo-K4s generated per second since the start of the run.", p.AverageRate},
+		{"elapsed_seconds", "Duration of the run.", p.Elapsed.Seconds()},
+		{"queued_jobs", "Jobs waiting for a worker.", float64(p.QueuedJobs)},
+		{"queue_capacity", "Capacity of the queue of jobs.", float64(p.QueueCapacity)},
+		{"busy_workers", "Workers processing a job.", float64(p.BusyWorkers)},
+		{"workers", "Workers.", float64(p.Workers)},
+	} {
+		openMetricsFamily(&b, gauge.name, "gauge", gauge.help)
+		openMetricsSample(&b, gauge.name, "", gauge.value)
+	}
+
+	b.WriteString("# EOF\n")
+
+	return b.String()
+}
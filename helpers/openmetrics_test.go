@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatOpenMetrics(t *testing.T) {
+	output := FormatOpenMetrics(Progress{
+		Statistics: &Checkpoint{
+			Settings: Settings{
+				Metrics: []string{`Long "segments": min_segment_len=4`},
+			},
+			SimulationsCount: 1000,
+			Collections: map[string]uint{
+				"Same distribution shapes": 25,
+				"K4-like groups":           1,
+				`Long "segments"`:          3,
+			},
+			Simulations: map[string]uint{
+				"Same distribution shapes": 20,
+				"K4-like groups":           1,
+				`Long "segments"`:          2,
+			},
+		},
+		AverageRate: 100.5,
+		Elapsed:     10 * time.Second,
+		QueuedJobs:  12,
+		BusyWorkers: 3,
+		Workers:     4,
+	})
+
+	for _, expected := range []string{
+		"# TYPE k4nundrum_simulations counter\n",
+		"k4nundrum_simulations_total 1000\n",
+		`k4nundrum_collections_total{metric="Same distribution shapes"} 25` + "\n",
+		`k4nundrum_matching_simulations_total{metric="K4-like groups"} 1` + "\n",
+		`k4nundrum_matching_simulations_total{metric="Long \"segments\""} 2` + "\n",
+		"# TYPE k4nundrum_busy_workers gauge\n",
+		"k4nundrum_busy_workers 3\n",
+		"k4nundrum_simulations_per_second 100.5\n",
+		"k4nundrum_elapsed_seconds 10\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected: %q, got:\n%s", expected, output)
+		}
+	}
+
+	// metrics absent from the statistics are not exposed
+	if strings.Contains(output, "Cyclic alternation") {
+		t.Errorf("unexpected metric:\n%s", output)
+	}
+
+	if !strings.HasSuffix(output, "# EOF\n") {
+		t.Errorf("expected the EOF marker:\n%s", output)
+	}
+}
@@ -0,0 +1,258 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// GroupRecord is the structured representation of a group
+type GroupRecord struct {
+	Segments        []string       `json:"segments"`
+	LetterFrequency map[string]int `json:"letter_frequency"`
+
+	// letter frequency distribution shape (e.g., "5-4-4-3")
+	Signature string `json:"signature"`
+
+	// location of each segment in the ciphertext, if known
+	// (start and end offsets in symbols, the end being excluded)
+	Spans [][2]int `json:"spans,omitempty"`
+
+	// location of each segment in the original text of the ciphertext
+	// (e.g., a transcription laid out as on the sculpture), if its
+	// positions are kept (start and end offsets in characters,
+	// the end being excluded)
+	OriginalSpans [][2]int `json:"original_spans,omitempty"`
+}
+
+// AlternationRecord is the structured representation of the sequence
+// of the groups in the ciphertext
+type AlternationRecord struct {
+	// groups in the order they appear (e.g., [0, 1, 0, 1])
+	Sequence []int `json:"sequence"`
+
+	// strict, cyclic, palindromic, block, or none
+	Pattern string `json:"pattern"`
+
+	// probability that a random ordering of the segments follows the pattern
+	Probability float64 `json:"probability"`
+
+	// probability that a random ordering of the segments
+	// produces the sequence
+	SequenceProbability float64 `json:"sequence_probability"`
+}
+
+// Record is the structured representation of a collection of groups
+// with the same letter frequency distribution shapes
+type Record struct {
+	InputId            string        `json:"input_id,omitempty"`
+	Ciphertext         string        `json:"ciphertext"`
+	Separators         string        `json:"separators"`
+	SimulationId       uint          `json:"simulation_id,omitempty"`
+	Seed               *uint64       `json:"seed,omitempty"`
+	Groups             []GroupRecord `json:"groups"`
+	Similarity         float64       `json:"similarity"`
+	AppropriatelySized bool          `json:"appropriately_sized"`
+	Alternating        bool          `json:"alternating"`
+	K4Like             bool          `json:"k4_like"`
+
+	Alternation AlternationRecord `json:"alternation"`
+}
+
+// NewRecord returns the structured representation of a collection
+func NewRecord(
+	ciphertext string,
+	separators []rune,
+	simulationId uint,
+	gs []groups.Group,
+	classification Classification,
+) Record {
+	record := Record{
+		Ciphertext:         ciphertext,
+		Separators:         string(separators),
+		SimulationId:       simulationId,
+		Groups:             make([]GroupRecord, len(gs)),
+		Similarity:         1,
+		AppropriatelySized: classification.AppropriatelySized,
+		Alternating:        classification.Alternating,
+		K4Like:             classification.K4Like,
+		Alternation: AlternationRecord{
+			Sequence:            classification.Alternation.Sequence,
+			Pattern:             string(classification.Alternation.Pattern),
+			Probability:         classification.Alternation.Probability,
+			SequenceProbability: classification.Alternation.SequenceProbability,
+		},
+	}
+
+	for i, group := range gs {
+		frequency := make(map[string]int, len(group.LetterFrequency))
+		for k, v := range group.LetterFrequency {
+			frequency[string(k)] = v
+		}
+
+		record.Groups[i] = GroupRecord{
+			Segments:        group.Segments,
+			LetterFrequency: frequency,
+			Signature:       frequencies.ShapeSignature(group).String(),
+		}
+
+		if group.HasSpans() {
+			record.Groups[i].Spans = make([][2]int, len(group.Spans))
+			for j, span := range group.Spans {
+				record.Groups[i].Spans[j] = [2]int{span.Start, span.End}
+			}
+		}
+	}
+
+	return record
+}
+
+// SetOriginalSpans locates the segments of the groups in the original text
+// of the ciphertext, given the position of each of its symbols
+// (see NormalizeOptions.KeepPositions; nothing is set if the positions
+// are not those of the ciphertext)
+func (r *Record) SetOriginalSpans(positions []Position) {
+	if len(positions) == 0 || len(positions) != utf8.RuneCountInString(r.Ciphertext) {
+		return
+	}
+
+	for i, group := range r.Groups {
+		if group.Spans == nil {
+			continue
+		}
+
+		r.Groups[i].OriginalSpans = make([][2]int, len(group.Spans))
+		for j, span := range group.Spans {
+			// the segments are never empty
+			r.Groups[i].OriginalSpans[j] = [2]int{
+				positions[span[0]].Offset,
+				positions[span[1]-1].Offset + 1,
+			}
+		}
+	}
+}
+
+// Printer outputs records in a given format
+type Printer interface {
+	Print(record Record) error
+	Close() error
+}
+
+// GetPrinter returns the printer corresponding to a format
+// (text, json, or ndjson)
+func GetPrinter(format string, w io.Writer) (Printer, error) {
+	switch format {
+	case FormatText:
+		return &textPrinter{}, nil
+	case FormatJSON:
+		return &jsonPrinter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonPrinter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format: %q", format)
+	}
+}
+
+// textPrinter prints human-readable records
+type textPrinter struct{}
+
+func (p *textPrinter) Print(record Record) error {
+	PrintContext(
+		record.InputId,
+		record.Ciphertext,
+		[]rune(record.Separators),
+		record.SimulationId,
+		record.Seed,
+	)
+
+	if record.Similarity < 1 {
+		fmt.Printf("  Similarity: %.4f\n\n", record.Similarity)
+	}
+
+	for i, group := range record.Groups {
+		frequency := make(map[rune]int, len(group.LetterFrequency))
+		for k, v := range group.LetterFrequency {
+			frequency[[]rune(k)[0]] = v
+		}
+
+		PrintGroup(groups.Group{
+			Segments:        group.Segments,
+			LetterFrequency: frequency,
+		}, i)
+	}
+
+	// the alternation is unknown for the records built
+	// without a classification
+	if record.Alternation.Pattern != "" {
+		PrintAlternation(Alternation{
+			Sequence:            record.Alternation.Sequence,
+			Pattern:             AlternationPattern(record.Alternation.Pattern),
+			Probability:         record.Alternation.Probability,
+			SequenceProbability: record.Alternation.SequenceProbability,
+		})
+	}
+
+	return nil
+}
+
+func (p *textPrinter) Close() error {
+	return nil
+}
+
+// jsonPrinter prints the records as a single JSON array,
+// each record as soon as it is available (the array is closed
+// once the printer is)
+type jsonPrinter struct {
+	w       io.Writer
+	printed bool
+}
+
+func (p *jsonPrinter) Print(record Record) error {
+	element, err := json.MarshalIndent(record, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if !p.printed {
+		separator = "[\n  "
+		p.printed = true
+	}
+
+	_, err = fmt.Fprintf(p.w, "%s%s", separator, element)
+	return err
+}
+
+func (p *jsonPrinter) Close() error {
+	if !p.printed {
+		_, err := io.WriteString(p.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(p.w, "\n]\n")
+	return err
+}
+
+// ndjsonPrinter prints one JSON record per line
+// as soon as it is available
+type ndjsonPrinter struct {
+	encoder *json.Encoder
+}
+
+func (p *ndjsonPrinter) Print(record Record) error {
+	return p.encoder.Encode(record)
+}
+
+func (p *ndjsonPrinter) Close() error {
+	return nil
+}
@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/groups"
+)
+
+func TestPrinters(t *testing.T) {
+	record := NewRecord(
+		"ABCWDEF",
+		[]rune{'W'},
+		42,
+		[]groups.Group{
+			{
+				Segments:        []string{"ABC"},
+				LetterFrequency: map[rune]int{'A': 1, 'B': 1, 'C': 1},
+			},
+			{
+				Segments:        []string{"DEF"},
+				LetterFrequency: map[rune]int{'D': 1, 'E': 1, 'F': 1},
+			},
+		},
+		Classify("ABCWDEF", []groups.Group{
+			{Segments: []string{"ABC"}},
+			{Segments: []string{"DEF"}},
+		}),
+	)
+
+	for _, format := range []string{FormatJSON, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			printer, err := GetPrinter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if err := printer.Print(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := printer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			var records []Record
+			if format == FormatJSON {
+				if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				decoder := json.NewDecoder(&buf)
+				for decoder.More() {
+					var r Record
+					if err := decoder.Decode(&r); err != nil {
+						t.Fatal(err)
+					}
+					records = append(records, r)
+				}
+			}
+
+			if len(records) != 2 {
+				t.Fatalf("records — expected: 2, got: %d", len(records))
+			}
+
+			r := records[0]
+			if r.Separators != "W" ||
+				r.SimulationId != 42 ||
+				!r.AppropriatelySized ||
+				r.Groups[1].LetterFrequency["E"] != 1 ||
+				r.Groups[1].Signature != "1-1-1" ||
+				r.Alternation.Pattern != "strict" ||
+				len(r.Alternation.Sequence) != 2 {
+				t.Errorf("unexpected record: %+v", r)
+			}
+		})
+	}
+
+	if _, err := GetPrinter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestJSONPrinterStreaming(t *testing.T) {
+	records := []Record{
+		{Ciphertext: "ABCWDEF", Separators: "W"},
+		{Ciphertext: "ABCXDEF", Separators: "X"},
+	}
+
+	for count := 0; count <= len(records); count++ {
+		var buf bytes.Buffer
+		printer, err := GetPrinter(FormatJSON, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, record := range records[:count] {
+			written := buf.Len()
+			if err := printer.Print(record); err != nil {
+				t.Fatal(err)
+			}
+
+			// the records are not buffered until the printer is closed
+			if buf.Len() == written {
+				t.Errorf("%d records: expected the record to be printed", count)
+			}
+		}
+
+		if err := printer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// same output as the whole array encoded at once
+		expected, err := json.MarshalIndent(append([]Record{}, records[:count]...), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != string(expected)+"\n" {
+			t.Errorf("%d records — expected: %s, got: %s", count, expected, buf.String())
+		}
+	}
+}
+
+func TestSetOriginalSpans(t *testing.T) {
+	// "AB\nC D": C and D are on the second line
+	normalized, err := Normalize("AB\nC D", NormalizeOptions{KeepPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := Record{
+		Ciphertext: normalized.Ciphertext,
+		Groups: []GroupRecord{
+			{Spans: [][2]int{{0, 3}}},
+			{Spans: [][2]int{{3, 4}}},
+			{},
+		},
+	}
+	record.SetOriginalSpans(normalized.Positions)
+
+	expected := [][][2]int{{{0, 4}}, {{5, 6}}, nil}
+	for i, group := range record.Groups {
+		if !reflect.DeepEqual(group.OriginalSpans, expected[i]) {
+			t.Errorf("group %d — expected: %v, got: %v", i, expected[i], group.OriginalSpans)
+		}
+	}
+
+	// positions of another text
+	record.Groups[0].OriginalSpans = nil
+	record.SetOriginalSpans(normalized.Positions[:2])
+	if record.Groups[0].OriginalSpans != nil {
+		t.Errorf("expected no original spans, got: %v", record.Groups[0].OriginalSpans)
+	}
+}
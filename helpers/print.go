@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/glethuillier/K4nundrum/frequencies"
 	"github.com/glethuillier/K4nundrum/groups"
 )
 
@@ -12,19 +13,40 @@ type pair struct {
 	value int
 }
 
-// PrintContext prints the ciphertext, its separator,
-// and, if applicable, the simulation id
-func PrintContext(ciphertext string, separator rune, simulationId uint) {
-	fmt.Printf("\n> %s\n  Separator: %s",
+// PrintContext prints the ciphertext, its separators,
+// and, if applicable, the input id, the simulation id, and the seed
+func PrintContext(
+	inputId string,
+	ciphertext string,
+	separators []rune,
+	simulationId uint,
+	seed *uint64,
+) {
+	label := "Separator"
+	if len(separators) > 1 {
+		label = "Separators"
+	}
+
+	fmt.Println()
+	if inputId != "" {
+		fmt.Printf("# %s\n", inputId)
+	}
+
+	fmt.Printf("> %s\n  %s: %s",
 		ciphertext,
-		string(separator),
+		label,
+		string(separators),
 	)
 
-	if simulationId == 0 {
-		fmt.Printf("\n\n")
-	} else {
-		fmt.Printf("\tSimulation: #%d\n\n", simulationId)
+	if simulationId != 0 {
+		fmt.Printf("\tSimulation: #%d", simulationId)
 	}
+
+	if seed != nil {
+		fmt.Printf("\tSeed: %d", *seed)
+	}
+
+	fmt.Printf("\n\n")
 }
 
 // PrintGroup prints a group and its letter frequency
@@ -58,3 +80,40 @@ func PrintGroup(group groups.Group, i int) {
 	}
 	fmt.Printf("\n\n")
 }
+
+// PrintAlternation prints the sequence of the groups in the ciphertext,
+// its pattern, and its probability if the segments were randomly ordered
+func PrintAlternation(alternation Alternation) {
+	fmt.Printf("  Alternation:\t%s (%s", alternation.FormatSequence(), alternation.Pattern)
+	if alternation.Pattern != NoAlternation {
+		fmt.Printf(", p = %.4f", alternation.Probability)
+	}
+	fmt.Printf(")\n\n")
+}
+
+// PrintSubstitutions prints the candidate rewrites of a group
+// in the alphabet of the first group of the collection
+func PrintSubstitutions(
+	group groups.Group,
+	i int,
+	classes []frequencies.RankClass,
+	substitutions []frequencies.Substitution,
+) {
+	fmt.Printf("  Group %d → Group 1:\t", i+1)
+	for _, class := range classes {
+		fmt.Printf("%s  ", class)
+	}
+	fmt.Printf("\n  Candidates:\t%d (showing %d)\n",
+		frequencies.CountSubstitutions(classes),
+		len(substitutions),
+	)
+
+	for _, substitution := range substitutions {
+		fmt.Printf("  \t\t")
+		for _, segment := range substitution.Apply(group.Segments) {
+			fmt.Printf("%s ", segment)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+}
@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+)
+
+// Progress is the state of a running simulation
+type Progress struct {
+	// statistics of the simulation
+	Statistics *Checkpoint
+
+	// number of pseudo-K4s after which the simulation stops
+	// (0: no target)
+	Target uint
+
+	// simulations per second since the last progress
+	// and since the start of the run
+	Rate        float64
+	AverageRate float64
+
+	Elapsed time.Duration
+
+	// jobs waiting for a worker
+	QueuedJobs    int
+	QueueCapacity int
+
+	BusyWorkers int
+	Workers     int
+}
+
+// ETA returns the estimated time before the target is reached
+// (false: no target, or no simulation yet)
+func (p Progress) ETA() (time.Duration, bool) {
+	if p.Target == 0 || p.AverageRate <= 0 {
+		return 0, false
+	}
+
+	count := p.Statistics.SimulationsCount
+	if count >= p.Target {
+		return 0, true
+	}
+
+	seconds := float64(p.Target-count) / p.AverageRate
+
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second), true
+}
+
+// FormatProgress returns the lines describing the progress of a simulation:
+// throughput, workload, then the proportion of pseudo-K4s per metric
+func FormatProgress(p Progress) []string {
+	count := p.Statistics.SimulationsCount
+
+	simulations := fmt.Sprintf("%-25s\t%d", "Simulations", count)
+	if p.Target > 0 {
+		simulations += fmt.Sprintf("/%d", p.Target)
+	}
+	simulations += fmt.Sprintf(" (%.1f/s, average: %.1f/s)", p.Rate, p.AverageRate)
+
+	timing := fmt.Sprintf("%-25s\t%s", "Elapsed", p.Elapsed.Round(time.Second))
+	if eta, ok := p.ETA(); ok {
+		timing += fmt.Sprintf(", ETA: %s", eta)
+	}
+
+	lines := []string{
+		simulations,
+		timing,
+		fmt.Sprintf("%-25s\t%d/%d jobs, %d/%d busy workers",
+			"Queue",
+			p.QueuedJobs,
+			p.QueueCapacity,
+			p.BusyWorkers,
+			p.Workers,
+		),
+	}
+
+	for _, name := range p.Statistics.Settings.MetricNames() {
+		simulationsCount, ok := p.Statistics.Simulations[name]
+		if !ok {
+			continue
+		}
+
+		percentage := 0.0
+		if count > 0 {
+			percentage = float64(simulationsCount*100) / float64(count)
+		}
+
+		lines = append(lines, fmt.Sprintf("%-25s\t%.2f%%\t%10d", name, percentage, simulationsCount))
+	}
+
+	return lines
+}
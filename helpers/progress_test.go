@@ -0,0 +1,88 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatProgress(t *testing.T) {
+	progress := Progress{
+		Statistics: &Checkpoint{
+			SimulationsCount: 1000,
+			Simulations: map[string]uint{
+				"Same distribution shapes": 20,
+				"K4-like groups":           1,
+			},
+		},
+		Target:        3000,
+		Rate:          110,
+		AverageRate:   100,
+		Elapsed:       10 * time.Second,
+		QueuedJobs:    12,
+		QueueCapacity: 1000,
+		BusyWorkers:   3,
+		Workers:       4,
+	}
+
+	lines := FormatProgress(progress)
+
+	// throughput, timing, and workload, then the metrics recorded
+	if len(lines) != 5 {
+		t.Fatalf("lines — expected: 5, got: %d (%q)", len(lines), lines)
+	}
+
+	for i, expected := range []string{
+		"1000/3000 (110.0/s, average: 100.0/s)",
+		"10s, ETA: 20s",
+		"12/1000 jobs, 3/4 busy workers",
+		"2.00%",
+		"0.10%",
+	} {
+		if !strings.Contains(lines[i], expected) {
+			t.Errorf("line %d — expected: %q, got: %q", i, expected, lines[i])
+		}
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	type test struct {
+		name        string
+		progress    Progress
+		expectedETA time.Duration
+		expectedOk  bool
+	}
+
+	statistics := &Checkpoint{SimulationsCount: 500}
+
+	tests := []test{
+		{
+			name:     "no target",
+			progress: Progress{Statistics: statistics, AverageRate: 10},
+		},
+		{
+			name:     "no simulation yet",
+			progress: Progress{Statistics: statistics, Target: 1000},
+		},
+		{
+			name:        "target to reach",
+			progress:    Progress{Statistics: statistics, Target: 1000, AverageRate: 10},
+			expectedETA: 50 * time.Second,
+			expectedOk:  true,
+		},
+		{
+			name:       "target reached",
+			progress:   Progress{Statistics: statistics, Target: 100, AverageRate: 10},
+			expectedOk: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			eta, ok := tc.progress.ETA()
+			if eta != tc.expectedETA || ok != tc.expectedOk {
+				t.Errorf("expected: %s (%t), got: %s (%t)", tc.expectedETA, tc.expectedOk, eta, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	cryptorand "crypto/rand"
+	"math/big"
+	"math/rand/v2"
+)
+
+// RandomSource is a source of random integers
+type RandomSource interface {
+	// IntN returns a random integer in [0, n)
+	IntN(n int) int
+}
+
+// cryptoSource draws random integers from crypto/rand
+type cryptoSource struct{}
+
+func (cryptoSource) IntN(n int) int {
+	randomIndex, err := cryptorand.Int(
+		cryptorand.Reader,
+		big.NewInt(int64(n)),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return int(randomIndex.Int64())
+}
+
+// GetCryptoSource returns a nondeterministic random source
+func GetCryptoSource() RandomSource {
+	return cryptoSource{}
+}
+
+// GetSeededSource returns a deterministic random source. Each simulation
+// draws from its own stream, so that any pseudo-K4 can be regenerated
+// from the seed and its simulation id.
+func GetSeededSource(seed uint64, simulationId uint) RandomSource {
+	return rand.New(rand.NewPCG(seed, uint64(simulationId)))
+}
@@ -3,10 +3,13 @@ package helpers
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/glethuillier/K4nundrum/alphabets"
 	"github.com/glethuillier/K4nundrum/groups"
 )
 
@@ -14,31 +17,120 @@ type StatisticsRecorder struct {
 	mu       sync.Mutex
 	saveFile chan struct{}
 
+	// settings of the simulation
+	settings Settings
+
+	// human-readable statistics of the simulation
+	// ("": not saved)
+	statisticsFile string
+
+	// machine-readable state of the simulation
+	// ("": no checkpoint)
+	checkpointFile string
+
 	// number of generated pseudo-K4
 	simulationsCount uint
 
-	// pseudo-K4s with the same letter frequency
-	// distribution shapes
-	sameDistributionShapesCount uint
+	// metrics counted: the default ones, then the user-defined ones
+	metrics []Metric
+
+	// number of collections of groups with the same letter frequency
+	// distribution shapes per metric
+	collectionsWithMetric []uint
+
+	// number of pseudo-K4s with at least one collection of groups
+	// per metric (a pseudo-K4 can have several collections)
+	simulationsWithMetric []uint
+
+	// metrics already counted per pseudo-K4
+	countedMetrics map[uint][]bool
+
+	// metrics missing from the checkpoint of a resumed simulation
+	// (e.g., introduced since): they are not reported, as their counts
+	// would not cover all the pseudo-K4s
+	missingCollections []bool
+	missingSimulations []bool
+
+	// number of jobs analyzing each pseudo-K4
+	// (0: the collections are counted as soon as they are recorded)
+	jobsPerSimulation uint
 
-	// pseudo-K4s with the same shapes AND
-	// appropriately sized (i.e., no tiny group)
-	appropriatelySizedGroupsCount uint
+	// pseudo-K4s being analyzed, by simulation id: they are counted once
+	// all their jobs, and the ones of the previous pseudo-K4s, are completed
+	pending map[uint]*pendingSimulation
+
+	// target width of the confidence interval of the K4-like metric
+	// (0: no target)
+	targetIntervalWidth float64
+}
+
+// pendingSimulation is a pseudo-K4 being analyzed
+type pendingSimulation struct {
+	completedJobs uint
+
+	// number of collections of groups per metric
+	collections []uint
+}
+
+// indexes of the default metrics
+const (
+	sameShapesMetric = iota
+	appropriatelySizedMetric
+	alternatingMetric
+	cyclicMetric
+	palindromicMetric
+	blockMetric
+	k4LikeMetric
+)
 
-	// pseudo-K4s with the same shapes AND
-	// alternating groups (e.g., A|B|A|B|A|B)
-	alternatingGroupsCount uint
+// defaultMetrics are the metrics counted by all simulations
+var defaultMetrics = []Metric{
+	{Name: "Same distribution shapes"},
+	{Name: "Groups length > 2", Criteria: Criteria{MinSegmentLength: 3}},
+	{Name: "Alternating groups", Criteria: Criteria{
+		Alternation: []AlternationPattern{StrictAlternation},
+	}},
+	{Name: "Cyclic alternation", Criteria: Criteria{
+		Alternation: []AlternationPattern{CyclicAlternation},
+	}},
+	{Name: "Palindromic alternation", Criteria: Criteria{
+		Alternation: []AlternationPattern{PalindromicAlternation},
+	}},
+	{Name: "Block alternation", Criteria: Criteria{
+		Alternation: []AlternationPattern{BlockAlternation},
+	}},
+	{Name: "K4-like groups", Criteria: Criteria{
+		MinSegmentLength: 3,
+		Alternation:      []AlternationPattern{StrictAlternation},
+	}},
+}
 
-	// K4-like pseudo-K4s
-	// (same shapes, non tiny, alternating)
-	k4LikeGroupsCount uint
+// reservedNames cannot name user-defined metrics
+// (settings of the statistics file)
+var reservedNames = []string{
+	"Seed",
+	"Null model",
+	"Alphabet",
+	"Ciphertext length",
+	"Separators",
+	"Metric",
+	"K4 metric",
 }
 
-const filename = "stats.txt"
+// StatisticsFilename is the default file in which the statistics are saved
+const StatisticsFilename = "stats.txt"
 
 func GetStatisticsRecorder() *StatisticsRecorder {
 	stats := &StatisticsRecorder{
-		saveFile: make(chan struct{}),
+		saveFile:              make(chan struct{}, 1),
+		statisticsFile:        StatisticsFilename,
+		metrics:               slices.Clone(defaultMetrics),
+		collectionsWithMetric: make([]uint, len(defaultMetrics)),
+		simulationsWithMetric: make([]uint, len(defaultMetrics)),
+		countedMetrics:        make(map[uint][]bool),
+		missingCollections:    make([]bool, len(defaultMetrics)),
+		missingSimulations:    make([]bool, len(defaultMetrics)),
+		pending:               make(map[uint]*pendingSimulation),
 	}
 
 	go func() {
@@ -47,9 +139,9 @@ func GetStatisticsRecorder() *StatisticsRecorder {
 		for {
 			select {
 			case <-ticker.C:
-				stats.save()
+				stats.Save()
 			case <-stats.saveFile:
-				stats.save()
+				stats.Save()
 			}
 		}
 	}()
@@ -62,7 +154,7 @@ func GetStatisticsRecorder() *StatisticsRecorder {
 func segmentsAreAppropriatelySized(gs []groups.Group) bool {
 	for _, g := range gs {
 		for _, segment := range g.Segments {
-			if len(segment) < 3 {
+			if utf8.RuneCountInString(segment) < 3 {
 				return false
 			}
 		}
@@ -70,179 +162,523 @@ func segmentsAreAppropriatelySized(gs []groups.Group) bool {
 	return true
 }
 
-// groupsAlternate identifies whether groups are alternating in
-// the ciphertext or not
+// groupsAlternate identifies whether groups are strictly alternating
+// in the ciphertext or not
 // (this function supports an arbirtrary number of groups)
 func groupsAlternate(ciphertext string, gs []groups.Group) bool {
-	segmentsPerGroup := make(map[string]int)
-	var (
-		allSegments []string
+	return AnalyzeAlternation(ciphertext, gs).Pattern == StrictAlternation
+}
+
+func formatSetting(setting, value string) string {
+	return fmt.Sprintf("%-25s\t%s\n", setting, value)
+}
 
-		// groups in the order they appear in the ciphertext
-		ciphertextOrderedGroups []int
+func formatStatistics(statsType string, count, totalCount uint) string {
+	return fmt.Sprintf("%-25s\t%.2f%%\t%10d/%d\n",
+		statsType,
+		float64(count*100)/float64(totalCount),
+		count,
+		totalCount,
 	)
+}
 
-	// first, label the groups
-	for i, group := range gs {
-		for _, segment := range group.Segments {
-			segmentsPerGroup[segment] = i
-			allSegments = append(allSegments, segment)
-		}
+// formatInterval formats a metric counting pseudo-K4s, along with the
+// empirical p-value of the observation made on K4 if K4 meets the metric
+func formatInterval(statsType string, count, totalCount uint, observed bool) string {
+	low, high := WilsonInterval(count, totalCount)
+
+	statistics := fmt.Sprintf("%-25s\t%.2f%%\t[%.4f%%, %.4f%%]\t%10d/%d",
+		statsType,
+		float64(count*100)/float64(totalCount),
+		low*100,
+		high*100,
+		count,
+		totalCount,
+	)
+
+	if observed {
+		statistics += fmt.Sprintf("\tp = %.6f", EmpiricalPValue(count, totalCount))
 	}
 
-	// then, create a slice that contains the labels of the groups
-	// in the order they appear in the ciphertext
-	// (e.g., { 0, 1, 0, 1 })
-	for {
-		groupId, ciphertextLen, found := func() (int, int, bool) {
-			for _, segment := range allSegments {
-				if strings.HasPrefix(ciphertext, segment) {
-					ciphertext = strings.Replace(ciphertext, segment, "", 1)
-					return segmentsPerGroup[segment], len(ciphertext), true
-				}
-			}
+	return statistics + "\n"
+}
 
-			if len(ciphertext) > 0 {
-				// remove separator
-				ciphertext = ciphertext[1:]
-			}
+// statisticsHeader introduces the metrics counting pseudo-K4s
+const statisticsHeader = "Pseudo-K4s (95% Wilson confidence interval, " +
+	"empirical p-value of K4):"
 
-			return -1, len(ciphertext), false
-		}()
+// FormatStatistics returns the human-readable statistics of a simulation
+func FormatStatistics(checkpoint *Checkpoint) string {
+	seed := "none"
+	if checkpoint.Settings.Seed != nil {
+		seed = fmt.Sprint(*checkpoint.Settings.Seed)
+	}
 
-		if !found {
-			if ciphertextLen == 0 {
-				// done
-				break
-			} else {
-				// segment was not found because of separator:
-				// continue
-				continue
-			}
-		} else {
-			ciphertextOrderedGroups = append(ciphertextOrderedGroups, groupId)
-		}
+	statistics := formatSetting("Seed", seed)
+	statistics += formatSetting("Null model", checkpoint.Settings.NullModel)
+	if checkpoint.Settings.Alphabet != "" {
+		statistics += formatSetting("Alphabet", checkpoint.Settings.Alphabet)
+	}
+	statistics += formatSetting(
+		"Ciphertext length",
+		fmt.Sprint(checkpoint.Settings.CiphertextLength),
+	)
+	statistics += formatSetting(
+		"Separators",
+		strings.Join(checkpoint.Settings.Separators, ","),
+	)
+	for _, definition := range checkpoint.Settings.Metrics {
+		statistics += formatSetting("Metric", definition)
+	}
+	for _, name := range checkpoint.Settings.K4Metrics {
+		statistics += formatSetting("K4 metric", name)
 	}
 
-	gsLen := len(gs)
+	names := checkpoint.Settings.MetricNames()
 
-	// a) check the first groups
-	// (they should all differ)
-	//
-	// example: { 0, 1, x, x }
-	//            |  :
-	for i := 0; i < gsLen; i++ {
-		for j := 0; j < gsLen; j++ {
-			if i != j && ciphertextOrderedGroups[i] == ciphertextOrderedGroups[j] {
-				return false
-			}
+	for _, name := range names {
+		// statistics recorded before the metric was introduced
+		count, ok := checkpoint.Collections[name]
+		if !ok {
+			continue
 		}
+
+		statistics += formatStatistics(
+			name,
+			count,
+			checkpoint.SimulationsCount,
+		)
 	}
 
-	// b) check that groups are alternating
-	//
-	// example: { 0, 1, 0, 1 }
-	//            |  :  |  :
-	for i := 0; i < len(ciphertextOrderedGroups); i++ {
-		if ciphertextOrderedGroups[i] != ciphertextOrderedGroups[i%gsLen] {
-			return false
+	// the metrics above count collections of groups; the following ones
+	// count pseudo-K4s (i.e., binomial proportions)
+	if checkpoint.Simulations != nil {
+		statistics += "\n" + statisticsHeader + "\n"
+
+		for _, name := range names {
+			count, ok := checkpoint.Simulations[name]
+			if !ok {
+				continue
+			}
+
+			statistics += formatInterval(
+				name,
+				count,
+				checkpoint.SimulationsCount,
+				slices.Contains(checkpoint.Settings.K4Metrics, name),
+			)
 		}
 	}
 
-	return true
+	return statistics
 }
 
-func formatStatistics(statsType string, count, totalCount uint) string {
-	return fmt.Sprintf("%-25s\t%.2f%%\t%10d/%d\n",
-		statsType,
-		float64(count*100)/float64(totalCount),
-		count,
-		totalCount,
-	)
-}
+// Save writes the statistics of the simulation into the statistics file
+func (s *StatisticsRecorder) Save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *StatisticsRecorder) save() {
 	// do not save if the original K4 is analyzed
 	if s.simulationsCount == 0 {
 		return
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	if s.statisticsFile != "" {
+		if err := s.writeStatistics(); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing statistics: %s\n", err.Error())
+		}
+	}
 
-	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if s.checkpointFile != "" {
+		if err := s.snapshot().Write(s.checkpointFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing checkpoint: %s\n", err.Error())
+		}
+	}
+}
+
+// writeStatistics writes the human-readable statistics of the simulation
+func (s *StatisticsRecorder) writeStatistics() error {
+	file, err := os.OpenFile(s.statisticsFile, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	defer func() {
 		if err := file.Close(); err != nil {
-			fmt.Printf("error when closing file: %s", err.Error())
+			fmt.Fprintf(os.Stderr, "error when closing file: %s\n", err.Error())
 		}
 	}()
 
-	statistics := formatStatistics(
-		"Same distribution shapes",
-		s.sameDistributionShapesCount,
-		s.simulationsCount,
-	)
+	statistics := FormatStatistics(s.snapshot())
 
-	statistics += formatStatistics(
-		"Groups length > 2",
-		s.appropriatelySizedGroupsCount,
-		s.simulationsCount,
-	)
+	_, err = file.WriteString(statistics)
+	return err
+}
 
-	statistics += formatStatistics(
-		"Alternating groups",
-		s.alternatingGroupsCount,
-		s.simulationsCount,
-	)
+// snapshot returns the state of the simulation
+func (s *StatisticsRecorder) snapshot() *Checkpoint {
+	checkpoint := &Checkpoint{
+		Version:          checkpointVersion,
+		Settings:         s.settings,
+		SimulationsCount: s.simulationsCount,
+		Collections:      make(map[string]uint),
+		Simulations:      make(map[string]uint),
+	}
+
+	for m, metric := range s.metrics {
+		if !s.missingCollections[m] {
+			checkpoint.Collections[metric.Name] = s.collectionsWithMetric[m]
+		}
+		if !s.missingSimulations[m] {
+			checkpoint.Simulations[metric.Name] = s.simulationsWithMetric[m]
+		}
+	}
+
+	return checkpoint
+}
+
+// Snapshot returns the state of the simulation
+func (s *StatisticsRecorder) Snapshot() *Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot()
+}
+
+// Restore resumes a simulation from its checkpoint
+// (the settings of the simulation, including its metrics,
+// are restored as well)
+func (s *StatisticsRecorder) Restore(checkpoint *Checkpoint) error {
+	metrics, err := checkpoint.Settings.GetMetrics()
+	if err != nil {
+		return err
+	}
+
+	if err := s.SetMetrics(metrics); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ok bool
+
+	s.settings = checkpoint.Settings
+	s.simulationsCount = checkpoint.SimulationsCount
+	s.pending = make(map[uint]*pendingSimulation)
+
+	for m, metric := range s.metrics {
+		s.collectionsWithMetric[m], ok = checkpoint.Collections[metric.Name]
+		s.missingCollections[m] = !ok
+
+		s.simulationsWithMetric[m], ok = checkpoint.Simulations[metric.Name]
+		s.missingSimulations[m] = !ok
+	}
+
+	return nil
+}
+
+// SetMetrics sets the user-defined metrics counted
+// along with the default ones
+func (s *StatisticsRecorder) SetMetrics(metrics []Metric) error {
+	names := make(map[string]bool)
+	for _, metric := range defaultMetrics {
+		names[metric.Name] = true
+	}
+
+	definitions := make([]string, len(metrics))
+	for i, metric := range metrics {
+		if names[metric.Name] || slices.Contains(reservedNames, metric.Name) {
+			return fmt.Errorf("metric %q: name already used", metric.Name)
+		}
+		names[metric.Name] = true
+		definitions[i] = metric.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics = append(slices.Clone(defaultMetrics), metrics...)
+	s.collectionsWithMetric = make([]uint, len(s.metrics))
+	s.simulationsWithMetric = make([]uint, len(s.metrics))
+	s.missingCollections = make([]bool, len(s.metrics))
+	s.missingSimulations = make([]bool, len(s.metrics))
+	s.countedMetrics = make(map[uint][]bool)
+	s.pending = make(map[uint]*pendingSimulation)
+
+	s.settings.K4Metrics = nil
+	s.settings.Metrics = nil
+	if len(definitions) > 0 {
+		s.settings.Metrics = definitions
+	}
 
-	statistics += formatStatistics(
-		"K4-like groups",
-		s.k4LikeGroupsCount,
+	return nil
+}
+
+// SetStatisticsFile sets the file in which the human-readable statistics
+// of the simulation are saved ("": not saved)
+func (s *StatisticsRecorder) SetStatisticsFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statisticsFile = path
+}
+
+// SetCheckpointFile sets the file in which the state of the simulation
+// is saved along with the statistics
+func (s *StatisticsRecorder) SetCheckpointFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpointFile = path
+}
+
+// ObserveK4 records the metrics met by the collections of groups of K4
+// (with the same alphabet and separators as the pseudo-K4s), so that the
+// empirical p-value of the observation made on K4 is reported for them
+func (s *StatisticsRecorder) ObserveK4(ciphertext string, collections [][]groups.Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.K4Metrics = nil
+
+	for _, metric := range s.metrics {
+		for _, gs := range collections {
+			if metric.Criteria.Match(gs, Classify(ciphertext, gs)) {
+				s.settings.K4Metrics = append(s.settings.K4Metrics, metric.Name)
+				break
+			}
+		}
+	}
+}
+
+// SetSeed records the seed of the pseudo-K4s generator
+func (s *StatisticsRecorder) SetSeed(seed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.Seed = &seed
+}
+
+// SetNullModel records the generator of the pseudo-K4s
+func (s *StatisticsRecorder) SetNullModel(nullModel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.NullModel = nullModel
+}
+
+// SetAlphabet records the alphabet of the pseudo-K4s
+func (s *StatisticsRecorder) SetAlphabet(alphabet alphabets.Alphabet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.Alphabet = alphabet.Name()
+}
+
+// SetCiphertextLength records the length of the pseudo-K4s
+func (s *StatisticsRecorder) SetCiphertextLength(length int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.CiphertextLength = length
+}
+
+// SetSeparatorSets records the sets of letters acting as separators
+func (s *StatisticsRecorder) SetSeparatorSets(separatorSets [][]rune) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings.Separators = make([]string, len(separatorSets))
+	for i, separators := range separatorSets {
+		s.settings.Separators[i] = string(separators)
+	}
+}
+
+// GetSettings returns the settings of the simulation
+func (s *StatisticsRecorder) GetSettings() Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.settings
+}
+
+// SetTargetIntervalWidth sets the width of the confidence interval of the
+// K4-like metric below which the simulation can stop
+func (s *StatisticsRecorder) SetTargetIntervalWidth(width float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.targetIntervalWidth = width
+}
+
+// TargetReached identifies whether the confidence interval of the K4-like
+// metric is narrower than the target width or not
+func (s *StatisticsRecorder) TargetReached() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.targetIntervalWidth <= 0 {
+		return false
+	}
+
+	low, high := WilsonInterval(
+		s.simulationsWithMetric[k4LikeMetric],
 		s.simulationsCount,
 	)
 
-	if _, err = file.WriteString(statistics); err != nil {
-		fmt.Printf("error writing file: %s", err.Error())
+	return high-low < s.targetIntervalWidth
+}
+
+// SetJobsPerSimulation sets the number of jobs analyzing each pseudo-K4:
+// a pseudo-K4 is then counted, along with its collections of groups, once
+// all its jobs and the ones of the previous pseudo-K4s are completed
+// (so that the statistics saved never include pseudo-K4s partially analyzed)
+func (s *StatisticsRecorder) SetJobsPerSimulation(jobs uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobsPerSimulation = jobs
+}
+
+// getPending returns a pseudo-K4 being analyzed
+func (s *StatisticsRecorder) getPending(simulationId uint) *pendingSimulation {
+	pending, ok := s.pending[simulationId]
+	if !ok {
+		pending = &pendingSimulation{collections: make([]uint, len(s.metrics))}
+		s.pending[simulationId] = pending
+	}
+
+	return pending
+}
+
+// CompleteJob records the completion of a job analyzing a pseudo-K4
+func (s *StatisticsRecorder) CompleteJob(simulationId uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.getPending(simulationId).completedJobs++
+
+	// count the pseudo-K4s completed without gap
+	// (e.g., a resumed simulation restarts from the next simulation id)
+	for {
+		next, ok := s.pending[s.simulationsCount+1]
+		if !ok || next.completedJobs < s.jobsPerSimulation {
+			return
+		}
+
+		for m, count := range next.collections {
+			if count > 0 {
+				s.collectionsWithMetric[m] += count
+				s.simulationsWithMetric[m]++
+			}
+		}
+
+		delete(s.pending, s.simulationsCount+1)
+		s.simulationsCount++
 	}
 }
 
 func (s *StatisticsRecorder) Update(simulationsCount uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.simulationsCount = simulationsCount
 }
 
-func (s *StatisticsRecorder) Record(ciphertext string, gs []groups.Group) {
-	// same distribution shapes
-	s.sameDistributionShapesCount++
+// Classification holds the characteristics of a collection of groups
+// with the same letter frequency distribution shapes
+type Classification struct {
+	AppropriatelySized bool
+	Alternating        bool
+	K4Like             bool
 
-	// same distribution shapes AND groups > 3
-	segmentsAreAppropriatelySized := segmentsAreAppropriatelySized(gs)
-	if segmentsAreAppropriatelySized {
-		s.appropriatelySizedGroupsCount++
-	}
+	// sequence of the groups in the ciphertext and its pattern
+	Alternation Alternation
+}
 
-	// same distribution shapes AND alternating groups
-	groupsAlternate := groupsAlternate(ciphertext, gs)
-	if groupsAlternate {
-		s.alternatingGroupsCount++
+// Classify identifies the characteristics of a collection of groups
+func Classify(ciphertext string, gs []groups.Group) Classification {
+	c := Classification{
+		AppropriatelySized: segmentsAreAppropriatelySized(gs),
+		Alternation:        AnalyzeAlternation(ciphertext, gs),
 	}
 
-	// same distribution shapes AND groups > 2 AND alternates
+	// strictly alternating groups (e.g., A|B|A|B|A|B)
+	c.Alternating = c.Alternation.Pattern == StrictAlternation
+
+	// groups > 2 AND alternates
 	// (K4-like pseudo-K4s)
-	if segmentsAreAppropriatelySized && groupsAlternate {
-		s.k4LikeGroupsCount++
+	c.K4Like = c.AppropriatelySized && c.Alternating
+
+	return c
+}
+
+// countSimulation counts a pseudo-K4 for a metric,
+// unless it has already been counted
+func (s *StatisticsRecorder) countSimulation(simulationId uint, m int) {
+	counted, ok := s.countedMetrics[simulationId]
+	if !ok {
+		counted = make([]bool, len(s.metrics))
+		s.countedMetrics[simulationId] = counted
 	}
 
-	s.saveFile <- struct{}{}
+	if counted[m] {
+		return
+	}
+
+	counted[m] = true
+	s.simulationsWithMetric[m]++
+}
+
+func (s *StatisticsRecorder) Record(
+	simulationId uint,
+	ciphertext string,
+	gs []groups.Group,
+) Classification {
+	classification := Classify(ciphertext, gs)
+
+	s.mu.Lock()
+
+	// same distribution shapes AND the criteria of each metric
+	// (e.g., groups > 2 AND alternating groups for K4-like groups)
+	for m, metric := range s.metrics {
+		if !metric.Criteria.Match(gs, classification) {
+			continue
+		}
+
+		// the pseudo-K4 is counted once completed
+		if s.jobsPerSimulation > 0 {
+			s.getPending(simulationId).collections[m]++
+			continue
+		}
+
+		s.collectionsWithMetric[m]++
+		s.countSimulation(simulationId, m)
+	}
+
+	s.mu.Unlock()
+
+	// request a save without waiting for it
+	// (a pending request already covers this collection)
+	select {
+	case s.saveFile <- struct{}{}:
+	default:
+	}
+
+	return classification
 }
 
 func (s *StatisticsRecorder) GetSameShapesCount() uint {
-	return s.sameDistributionShapesCount
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.collectionsWithMetric[sameShapesMetric]
 }
 
 func (s *StatisticsRecorder) GetK4LikeCount() uint {
-	return s.k4LikeGroupsCount
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.collectionsWithMetric[k4LikeMetric]
 }
@@ -1,6 +1,11 @@
 package helpers
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/glethuillier/K4nundrum/groups"
@@ -292,35 +297,48 @@ func TestRecord(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			recorder := GetStatisticsRecorder()
-			recorder.Record(tc.cipher, tc.groups)
+			recorder.Record(1, tc.cipher, tc.groups)
 
-			if recorder.appropriatelySizedGroupsCount != tc.segmentsAppropriatelySized {
+			if recorder.collectionsWithMetric[appropriatelySizedMetric] != tc.segmentsAppropriatelySized {
 				t.Errorf("appropriately sized groups — expected: %d, got %d",
 					tc.segmentsAppropriatelySized,
-					recorder.appropriatelySizedGroupsCount,
+					recorder.collectionsWithMetric[appropriatelySizedMetric],
 				)
 			}
 
-			if recorder.alternatingGroupsCount != tc.groupsAlternate {
+			if recorder.collectionsWithMetric[alternatingMetric] != tc.groupsAlternate {
 				t.Errorf("alternating groups — expected: %d, got %d",
 					tc.groupsAlternate,
-					recorder.alternatingGroupsCount,
+					recorder.collectionsWithMetric[alternatingMetric],
 				)
 			}
 
-			if recorder.k4LikeGroupsCount != tc.k4Like {
+			if recorder.collectionsWithMetric[k4LikeMetric] != tc.k4Like {
 				t.Errorf("K4-like — expected: %d, got %d",
 					tc.k4Like,
-					recorder.k4LikeGroupsCount,
+					recorder.collectionsWithMetric[k4LikeMetric],
 				)
 			}
 		})
 	}
 }
 
+// getTestRecorder returns a recorder saving its statistics
+// in a temporary directory
+func getTestRecorder(t *testing.T) *StatisticsRecorder {
+	recorder := GetStatisticsRecorder()
+	recorder.SetStatisticsFile(filepath.Join(t.TempDir(), StatisticsFilename))
+
+	// the statistics may be saved in the background:
+	// stop saving them before the directory is removed
+	t.Cleanup(func() { recorder.SetStatisticsFile("") })
+
+	return recorder
+}
+
 func TestUpdate(t *testing.T) {
 	expectedSimulationsCount := 0
-	recorder := GetStatisticsRecorder()
+	recorder := getTestRecorder(t)
 
 	for i := 0; i < 10_000; i++ {
 		recorder.Update(uint(i))
@@ -333,3 +351,159 @@ func TestUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordSimulations(t *testing.T) {
+	recorder := getTestRecorder(t)
+	recorder.Update(3)
+
+	k4LikeGroups := []groups.Group{
+		{Segments: []string{"ABC", "GHI"}},
+		{Segments: []string{"DEF", "JKL"}},
+	}
+
+	// two K4-like collections of the same pseudo-K4
+	recorder.Record(1, "ABCDEFGHIJKL", k4LikeGroups)
+	recorder.Record(1, "ABCDEFGHIJKL", k4LikeGroups)
+
+	// one K4-like collection of another pseudo-K4
+	recorder.Record(3, "ABCDEFGHIJKL", k4LikeGroups)
+
+	if recorder.collectionsWithMetric[k4LikeMetric] != 3 {
+		t.Errorf("K4-like groups — expected: 3, got %d", recorder.collectionsWithMetric[k4LikeMetric])
+	}
+
+	if recorder.simulationsWithMetric[k4LikeMetric] != 2 {
+		t.Errorf("K4-like pseudo-K4s — expected: 2, got %d",
+			recorder.simulationsWithMetric[k4LikeMetric],
+		)
+	}
+}
+
+func TestTargetReached(t *testing.T) {
+	recorder := getTestRecorder(t)
+	recorder.Update(100)
+
+	if recorder.TargetReached() {
+		t.Error("expected no target")
+	}
+
+	// no K4-like pseudo-K4 out of 100: [0%, 3.70%]
+	recorder.SetTargetIntervalWidth(0.01)
+	if recorder.TargetReached() {
+		t.Error("expected a target not reached")
+	}
+
+	// no K4-like pseudo-K4 out of 1000: [0%, 0.38%]
+	recorder.Update(1000)
+	if !recorder.TargetReached() {
+		t.Error("expected a target reached")
+	}
+}
+
+func TestSaveStatistics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StatisticsFilename)
+
+	recorder := getTestRecorder(t)
+	recorder.SetStatisticsFile(path)
+	recorder.Update(1)
+	recorder.Save()
+
+	statistics, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(statistics), "Same distribution shapes") {
+		t.Errorf("unexpected statistics: %s", statistics)
+	}
+}
+
+func TestWriteStatisticsError(t *testing.T) {
+	// a directory cannot be opened as the file of the statistics
+	recorder := getTestRecorder(t)
+	recorder.SetStatisticsFile(t.TempDir())
+	recorder.Update(1)
+
+	if err := recorder.writeStatistics(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCompleteJob(t *testing.T) {
+	recorder := getTestRecorder(t)
+	recorder.SetJobsPerSimulation(2)
+
+	k4LikeGroups := []groups.Group{
+		{Segments: []string{"ABC", "GHI"}},
+		{Segments: []string{"DEF", "JKL"}},
+	}
+
+	// the second pseudo-K4 is completed before the first one
+	recorder.Record(2, "ABCDEFGHIJKL", k4LikeGroups)
+	recorder.CompleteJob(2)
+	recorder.CompleteJob(2)
+	recorder.Record(1, "ABCDEFGHIJKL", k4LikeGroups)
+	recorder.CompleteJob(1)
+
+	snapshot := recorder.Snapshot()
+	if snapshot.SimulationsCount != 0 || snapshot.Collections["K4-like groups"] != 0 {
+		t.Errorf("expected no pseudo-K4 counted, got: %+v", snapshot)
+	}
+
+	recorder.CompleteJob(1)
+
+	snapshot = recorder.Snapshot()
+	if snapshot.SimulationsCount != 2 ||
+		snapshot.Collections["K4-like groups"] != 2 ||
+		snapshot.Simulations["K4-like groups"] != 2 {
+		t.Errorf("expected two pseudo-K4s counted, got: %+v", snapshot)
+	}
+
+	// the third pseudo-K4 is still being analyzed
+	recorder.Record(3, "ABCDEFGHIJKL", k4LikeGroups)
+	recorder.CompleteJob(3)
+
+	if count := recorder.Snapshot().SimulationsCount; count != 2 {
+		t.Errorf("simulations count — expected: 2, got: %d", count)
+	}
+}
+
+func TestObserveK4(t *testing.T) {
+	recorder := getTestRecorder(t)
+	recorder.Update(10)
+
+	// two groups of segments longer than 2, in blocks (A|A|B|B)
+	recorder.ObserveK4("ABCGHIDEFJKL", [][]groups.Group{{
+		{Segments: []string{"ABC", "GHI"}},
+		{Segments: []string{"DEF", "JKL"}},
+	}})
+
+	snapshot := recorder.Snapshot()
+	expected := []string{"Same distribution shapes", "Groups length > 2", "Block alternation"}
+	if !reflect.DeepEqual(snapshot.Settings.K4Metrics, expected) {
+		t.Fatalf("K4 metrics — expected: %v, got: %v", expected, snapshot.Settings.K4Metrics)
+	}
+
+	// the empirical p-value is only reported for the metrics met by K4
+	statistics := FormatStatistics(snapshot)
+	for _, line := range strings.Split(statistics, "\n") {
+		if !strings.Contains(line, "[") {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.Split(line, "\t")[0])
+		if hasPValue := strings.Contains(line, "p = "); hasPValue != slices.Contains(expected, name) {
+			t.Errorf("%s — expected a p-value: %t, got: %q", name, !hasPValue, line)
+		}
+	}
+
+	// the metrics met by K4 are saved along with the settings
+	parsed, err := ParseStatistics(strings.NewReader(statistics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed.Settings.K4Metrics, expected) {
+		t.Errorf("K4 metrics — expected: %v, got: %v", expected, parsed.Settings.K4Metrics)
+	}
+}
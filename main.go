@@ -1,18 +1,9 @@
 package main
 
 import (
-	"context"
-	"flag"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"sync"
-	"syscall"
-
-	"github.com/glethuillier/K4nundrum/frequencies"
-	"github.com/glethuillier/K4nundrum/groups"
-	"github.com/glethuillier/K4nundrum/helpers"
 )
 
 const (
@@ -22,168 +13,116 @@ const (
 		"VTTMZFPKWGDKZXTJCDIGKUHUAUEKCAR"
 )
 
-type Job struct {
-	ciphertext   string
-	separator    rune
-	simulationId uint
-}
-
-// getValidCollections returns collections of groups with
-// identical letters frequency distribution shapes
-func getValidCollections(
-	generator *groups.GroupsGenerator,
-	permutation []string,
-) []*groups.Collection {
-	var validCollections []*groups.Collection
-
-	for _, collection := range generator.GetSuitableCollections(permutation) {
-		if frequencies.HaveIdenticalShapes(collection) {
-			validCollections = append(validCollections, collection)
-		}
-	}
+// exit codes
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
 
-	return validCollections
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
 }
 
-func runAnalysis(
-	ctx context.Context,
-	mu *sync.Mutex,
-	job *Job,
-	recorder *helpers.StatisticsRecorder,
-) {
+var commands = []command{
+	{
+		name:        "analyze",
+		description: "analyze K4 (default) or an arbitrary ciphertext",
+		run:         runAnalyze,
+	},
+	{
+		name:        "simulate",
+		description: "analyze random pseudo-K4s and record statistics",
+		run:         runSimulate,
+	},
+	{
+		name:        "merge",
+		description: "combine the statistics of several simulations",
+		run:         runMerge,
+	},
+	{
+		name:        "report",
+		description: "print the statistics of a simulation",
+		run:         runReport,
+	},
+	{
+		name:        "serve",
+		description: "expose the analysis over HTTP",
+		run:         runServe,
+	},
+}
 
-	// the separator should be immediately surrounded by nonseparators
-	// (e.g., a ciphertext containing a doublet separator 'XX' should be excluded)
-	for i := 0; i < len(job.ciphertext)-1; i++ {
-		if job.ciphertext[i] == byte(job.separator) && job.ciphertext[i+1] == byte(job.separator) {
-			return
-		}
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [options]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.description)
 	}
+	fmt.Fprintf(os.Stderr,
+		"\nRun '%s {{command}} -h' for the options of a command.\n"+
+			"Exit codes: %d (success), %d (failure), %d (invalid usage).\n",
+		os.Args[0],
+		exitSuccess,
+		exitFailure,
+		exitUsage,
+	)
+}
 
-	// generate permutations of segments split based on a separator
-	// example: "AAXBBXC" and separator 'X':
-	// "AA", "BB", "C"; "AA", "C", "BB"; etc.
-	generator := groups.GetGroupsGenerator()
-	for permutation := range helpers.GeneratePermutations(
-		helpers.Split(job.ciphertext, job.separator),
-	) {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			// analyze the collections to identify groups with
-			// the same letters frequency shapes
-			for _, collection := range getValidCollections(generator, permutation) {
-				mu.Lock()
-
-				helpers.PrintContext(job.ciphertext, job.separator, job.simulationId)
-				for j, group := range collection.Groups {
-					helpers.PrintGroup(group, j)
-				}
-
-				recorder.Record(job.ciphertext, collection.Groups)
-				mu.Unlock()
-			}
-		}
+// isSimFlag identifies the simulation flag of the former command line
+// (e.g., --sim)
+func isSimFlag(arg string) bool {
+	switch arg {
+	case "-sim", "--sim", "-sim=true", "--sim=true":
+		return true
+	default:
+		return false
 	}
 }
 
-func main() {
-	var (
-		wg               sync.WaitGroup
-		simulationsCount uint
-		mu               sync.Mutex
-	)
-
-	sim := flag.Bool("sim", false, "simulation mode")
-	customCiphertext := flag.String(
-		"ciphertext",
-		"",
-		"custom analysis of an arbitrary ciphertext",
-	)
-	workersCount := flag.Int(
-		"workers",
-		20,
-		"number of workers to process the analysis in parallel",
-	)
-	flag.Parse()
-
-	ctx, cancelFunc := context.WithCancel(context.Background())
-
-	terminateAnalysis := make(chan os.Signal, 1)
-	jobs := make(chan Job, 1000)
-
-	simulation := *sim
-	recorder := helpers.GetStatisticsRecorder()
-
-	// start workers
-	for w := 1; w <= *workersCount; w++ {
-		wg.Add(1)
-		go func(ctx context.Context, job <-chan Job, wg *sync.WaitGroup) {
-			defer wg.Done()
-
-			for {
-				select {
-				case j, ok := <-job:
-					if !ok {
-						return
-					}
-					runAnalysis(ctx, &mu, &j, recorder)
-				case <-ctx.Done():
-					return
-				}
-			}
-		}(ctx, jobs, &wg)
+func run(args []string) int {
+	// without command: analyze K4
+	if len(args) == 0 {
+		return runAnalyze(args)
 	}
 
-	ciphertext := k4
-
-	go func() {
-		for {
-			if simulation {
-				// if simulation is enabled:
-				// generate a random pseudo-K4
-				ciphertext = helpers.GenerateRandomString(len(k4))
-				simulationsCount++
-				recorder.Update(simulationsCount)
-			} else if *customCiphertext != "" {
-				ciphertext = strings.ToUpper(*customCiphertext)
-			}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return exitSuccess
+	}
 
-			// iterate over separators:
-			// 'A', 'B', ..., 'Z'
-			for separator := 'A'; separator <= 'Z'; separator++ {
-				jobs <- Job{
-					ciphertext:   ciphertext,
-					separator:    separator,
-					simulationId: simulationsCount,
-				}
+	// options without command: former command line
+	// (--sim for simulations, analysis otherwise)
+	if strings.HasPrefix(args[0], "-") {
+		var remainingArgs []string
+		simulation := false
+
+		for _, arg := range args {
+			if isSimFlag(arg) {
+				simulation = true
+			} else {
+				remainingArgs = append(remainingArgs, arg)
 			}
+		}
 
-			// if K4 has been analyzed:
-			// exit gracefully
-			if !simulation {
-				// signal that all jobs have been sent
-				// and wait for the workers to finish
-				// their respective tasks
-				close(jobs)
-				wg.Wait()
+		if simulation {
+			return runSimulate(remainingArgs)
+		}
+		return runAnalyze(remainingArgs)
+	}
 
-				terminateAnalysis <- syscall.SIGTERM
-				break
-			}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
 		}
-	}()
+	}
 
-	signal.Notify(
-		terminateAnalysis,
-		syscall.SIGINT,
-		syscall.SIGTERM,
-	)
+	fmt.Fprintf(os.Stderr, "unknown command: %q\n\n", args[0])
+	usage()
+	return exitUsage
+}
 
-	<-terminateAnalysis
-	fmt.Println("Analysis Completed.")
-	fmt.Printf("Same shapes:\t%d\n", recorder.GetSameShapesCount())
-	fmt.Printf("K4-like:\t%d\n", recorder.GetK4LikeCount())
-	cancelFunc()
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
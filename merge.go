@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// runMerge combines the statistics of several simulations
+// (statistics or checkpoint files) and returns the exit code
+func runMerge(args []string) int {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(),
+			"Usage: %s merge [options] {{statistics or checkpoint files}}\n\n",
+			os.Args[0],
+		)
+		flags.PrintDefaults()
+	}
+
+	output := flags.String(
+		"output",
+		"",
+		"statistics file in which the combined statistics are written (default: standard output)",
+	)
+	checkpointOutput := flags.String(
+		"checkpoint",
+		"",
+		"checkpoint file in which the combined statistics are written",
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var checkpoints []*helpers.Checkpoint
+	for _, path := range flags.Args() {
+		checkpoint, err := helpers.LoadStatistics(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	merged, err := helpers.MergeStatistics(checkpoints)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitFailure
+	}
+
+	statistics := helpers.FormatStatistics(merged)
+
+	if *output == "" {
+		fmt.Print(statistics)
+	} else if err := os.WriteFile(*output, []byte(statistics), 0600); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitFailure
+	}
+
+	if *checkpointOutput != "" {
+		if err := merged.Write(*checkpointOutput); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+	}
+
+	return exitSuccess
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// serveMetrics exposes the metrics of a simulation in the OpenMetrics
+// text format (GET /metrics) until the server is closed
+func serveMetrics(addr string, m *monitor) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics endpoint: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", helpers.OpenMetricsContentType)
+		io.WriteString(w, helpers.FormatOpenMetrics(m.progress(time.Now())))
+	})
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics endpoint: %s\n", err.Error())
+		}
+	}()
+
+	return server, nil
+}
@@ -0,0 +1,55 @@
+package nullmodels
+
+import (
+	"strings"
+	"unicode"
+)
+
+// plaintexts of K1, K2, and K3 (including their misspellings)
+const (
+	k1Plaintext = "BETWEEN SUBTLE SHADING AND THE ABSENCE OF LIGHT " +
+		"LIES THE NUANCE OF IQLUSION"
+
+	k2Plaintext = "IT WAS TOTALLY INVISIBLE HOWS THAT POSSIBLE " +
+		"THEY USED THE EARTHS MAGNETIC FIELD X " +
+		"THE INFORMATION WAS GATHERED AND TRANSMITTED UNDERGRUUND " +
+		"TO AN UNKNOWN LOCATION X DOES LANGLEY KNOW ABOUT THIS " +
+		"THEY SHOULD ITS BURIED OUT THERE SOMEWHERE X " +
+		"WHO KNOWS THE EXACT LOCATION ONLY WW THIS WAS HIS LAST MESSAGE X " +
+		"THIRTY EIGHT DEGREES FIFTY SEVEN MINUTES SIX POINT FIVE SECONDS NORTH " +
+		"SEVENTY SEVEN DEGREES EIGHT MINUTES FORTY FOUR SECONDS WEST X LAYER TWO"
+
+	k3Plaintext = "SLOWLY DESPARATLY SLOWLY THE REMAINS OF PASSAGE DEBRIS " +
+		"THAT ENCUMBERED THE LOWER PART OF THE DOORWAY WAS REMOVED " +
+		"WITH TREMBLING HANDS I MADE A TINY BREACH IN THE UPPER LEFT HAND CORNER " +
+		"AND THEN WIDENING THE HOLE A LITTLE I INSERTED THE CANDLE AND PEERED IN " +
+		"THE HOT AIR ESCAPING FROM THE CHAMBER CAUSED THE FLAME TO FLICKER " +
+		"BUT PRESENTLY DETAILS OF THE ROOM WITHIN EMERGED FROM THE MIST X " +
+		"CAN YOU SEE ANYTHING Q"
+)
+
+// englishFrequencies are the relative frequencies of the letters
+// in English texts (in hundredths of a percent)
+var englishFrequencies = map[rune]int{
+	'A': 817, 'B': 129, 'C': 278, 'D': 425, 'E': 1270, 'F': 223,
+	'G': 202, 'H': 609, 'I': 697, 'J': 15, 'K': 77, 'L': 403,
+	'M': 241, 'N': 675, 'O': 751, 'P': 193, 'Q': 10, 'R': 599,
+	'S': 633, 'T': 906, 'U': 276, 'V': 98, 'W': 236, 'X': 15,
+	'Y': 197, 'Z': 7,
+}
+
+// GetKryptosCorpus returns the plaintexts of K1, K2, and K3
+func GetKryptosCorpus() string {
+	return NormalizeCorpus(k1Plaintext + k2Plaintext + k3Plaintext)
+}
+
+// NormalizeCorpus keeps the letters of a corpus, in uppercase
+func NormalizeCorpus(corpus string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToUpper(r)
+		if r < 'A' || r > 'Z' {
+			return -1
+		}
+		return r
+	}, corpus)
+}
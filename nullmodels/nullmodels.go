@@ -0,0 +1,377 @@
+package nullmodels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+const (
+	Uniform       = "uniform"
+	Shuffle       = "shuffle"
+	Unigram       = "unigram"
+	Markov        = "markov"
+	Vigenere      = "vigenere"
+	Transposition = "transposition"
+)
+
+// Names lists the available null models
+var Names = []string{Uniform, Shuffle, Unigram, Markov, Vigenere, Transposition}
+
+// markovOrder is the number of letters defining a state of the Markov chain
+const markovOrder = 2
+
+// NullModel generates pseudo-K4s
+type NullModel interface {
+	Name() string
+
+	// Generate generates a pseudo-K4 of a given size. The model only draws
+	// from the random source, so that seeded simulations are reproducible.
+	Generate(source helpers.RandomSource, size int) string
+}
+
+// Get returns a null model generating pseudo-K4s written in an alphabet.
+// The reference ciphertext is shuffled by the shuffle model, while the
+// corpus trains the Markov chain used by the markov, vigenere, and
+// transposition models (default: K1–K3 plaintexts). The models based on
+// English (unigram, markov, vigenere, and transposition) require an
+// alphabet covering the letters A–Z (e.g., latin, kryptos, or latin25).
+func Get(name, reference, corpus string, alphabet alphabets.Alphabet) (NullModel, error) {
+	if corpus == "" {
+		corpus = GetKryptosCorpus()
+	}
+
+	switch name {
+	case Unigram, Markov, Vigenere, Transposition:
+		if !coversEnglish(alphabet) {
+			return nil, fmt.Errorf(
+				"%s model: the %s alphabet does not cover the letters A–Z",
+				name,
+				alphabet.Name(),
+			)
+		}
+	}
+
+	switch name {
+	case Uniform:
+		return uniformModel{alphabet: alphabet}, nil
+	case Shuffle:
+		if reference == "" {
+			return nil, fmt.Errorf("%s model: empty reference ciphertext", name)
+		}
+
+		// the reference is written in the alphabet
+		// (e.g., J becomes I in the latin25 alphabet)
+		symbols := []rune(reference)
+		for i, c := range symbols {
+			symbol, ok := alphabet.Normalize(c)
+			if !ok {
+				return nil, fmt.Errorf("%s model: %q is not in the %s alphabet",
+					name,
+					c,
+					alphabet.Name(),
+				)
+			}
+			symbols[i] = symbol
+		}
+		return shuffleModel{reference: symbols}, nil
+	case Unigram:
+		weights := make(map[rune]int)
+		for letter, weight := range englishFrequencies {
+			symbol, _ := alphabet.Normalize(letter)
+			weights[symbol] += weight
+		}
+		return unigramModel{distribution: newDistribution(weights)}, nil
+	case Markov, Vigenere, Transposition:
+		chain, err := newMarkovChain(toAlphabet(corpus, alphabet), markovOrder)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case Vigenere:
+			return vigenereModel{english: chain, alphabet: alphabet}, nil
+		case Transposition:
+			return transpositionModel{english: chain}, nil
+		default:
+			return chain, nil
+		}
+	default:
+		return nil, fmt.Errorf(
+			"unknown null model: %q (available: %s)",
+			name,
+			strings.Join(Names, ", "),
+		)
+	}
+}
+
+// coversEnglish identifies whether the letters A–Z
+// can be written in an alphabet
+func coversEnglish(alphabet alphabets.Alphabet) bool {
+	for letter := 'A'; letter <= 'Z'; letter++ {
+		if _, ok := alphabet.Normalize(letter); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// toAlphabet writes the letters of a corpus in an alphabet
+// (e.g., J becomes I in the latin25 alphabet)
+func toAlphabet(corpus string, alphabet alphabets.Alphabet) string {
+	return strings.Map(func(r rune) rune {
+		symbol, _ := alphabet.Normalize(r)
+		return symbol
+	}, NormalizeCorpus(corpus))
+}
+
+// distribution draws letters according to their weights
+type distribution struct {
+	letters    []rune
+	cumulative []int
+}
+
+func newDistribution(weights map[rune]int) distribution {
+	var d distribution
+
+	// sort the letters to draw them deterministically
+	for letter := range weights {
+		d.letters = append(d.letters, letter)
+	}
+	sort.Slice(d.letters, func(i, j int) bool {
+		return d.letters[i] < d.letters[j]
+	})
+
+	total := 0
+	for _, letter := range d.letters {
+		total += weights[letter]
+		d.cumulative = append(d.cumulative, total)
+	}
+
+	return d
+}
+
+func (d distribution) draw(source helpers.RandomSource) rune {
+	n := source.IntN(d.cumulative[len(d.cumulative)-1])
+
+	return d.letters[sort.Search(len(d.cumulative), func(i int) bool {
+		return d.cumulative[i] > n
+	})]
+}
+
+// uniformModel draws symbols uniformly
+type uniformModel struct {
+	alphabet alphabets.Alphabet
+}
+
+func (uniformModel) Name() string {
+	return Uniform
+}
+
+func (m uniformModel) Generate(source helpers.RandomSource, size int) string {
+	return helpers.GenerateRandomString(source, m.alphabet, size)
+}
+
+// shuffleModel shuffles the letters of a reference ciphertext
+// (the letters are recycled if the pseudo-K4 is longer than the reference)
+type shuffleModel struct {
+	reference []rune
+}
+
+func (shuffleModel) Name() string {
+	return Shuffle
+}
+
+func (m shuffleModel) Generate(source helpers.RandomSource, size int) string {
+	var sb strings.Builder
+	sb.Grow(size)
+
+	letters := make([]rune, len(m.reference))
+
+	for generated := 0; generated < size; {
+		copy(letters, m.reference)
+
+		// Fisher–Yates shuffle
+		for i := len(letters) - 1; i > 0; i-- {
+			j := source.IntN(i + 1)
+			letters[i], letters[j] = letters[j], letters[i]
+		}
+
+		for _, letter := range letters {
+			if generated == size {
+				break
+			}
+			sb.WriteRune(letter)
+			generated++
+		}
+	}
+
+	return sb.String()
+}
+
+// unigramModel draws letters according to their frequencies in English
+type unigramModel struct {
+	distribution distribution
+}
+
+func (unigramModel) Name() string {
+	return Unigram
+}
+
+func (m unigramModel) Generate(source helpers.RandomSource, size int) string {
+	var sb strings.Builder
+	sb.Grow(size)
+
+	for i := 0; i < size; i++ {
+		sb.WriteRune(m.distribution.draw(source))
+	}
+
+	return sb.String()
+}
+
+// markovChain generates English-like texts: each letter is drawn according
+// to the letters following the previous ones in a corpus
+type markovChain struct {
+	order       int
+	states      []string
+	transitions map[string]distribution
+
+	// letters of the corpus, used when a state has no transition
+	// (e.g., the last letters of the corpus)
+	fallback distribution
+}
+
+func newMarkovChain(corpus string, order int) (*markovChain, error) {
+	corpus = NormalizeCorpus(corpus)
+	if len(corpus) <= order {
+		return nil, fmt.Errorf(
+			"%s model: the corpus must contain more than %d letters",
+			Markov,
+			order,
+		)
+	}
+
+	transitionsWeights := make(map[string]map[rune]int)
+	lettersWeights := make(map[rune]int)
+
+	for i, letter := range corpus {
+		lettersWeights[letter]++
+
+		if i < order {
+			continue
+		}
+
+		state := corpus[i-order : i]
+		if _, ok := transitionsWeights[state]; !ok {
+			transitionsWeights[state] = make(map[rune]int)
+		}
+		transitionsWeights[state][letter]++
+	}
+
+	chain := &markovChain{
+		order:       order,
+		transitions: make(map[string]distribution),
+		fallback:    newDistribution(lettersWeights),
+	}
+
+	for state, weights := range transitionsWeights {
+		chain.states = append(chain.states, state)
+		chain.transitions[state] = newDistribution(weights)
+	}
+	sort.Strings(chain.states)
+
+	return chain, nil
+}
+
+func (*markovChain) Name() string {
+	return Markov
+}
+
+func (m *markovChain) Generate(source helpers.RandomSource, size int) string {
+	text := []rune(m.states[source.IntN(len(m.states))])
+
+	for len(text) < size {
+		state := string(text[len(text)-m.order:])
+
+		if d, ok := m.transitions[state]; ok {
+			text = append(text, d.draw(source))
+		} else {
+			text = append(text, m.fallback.draw(source))
+		}
+	}
+
+	return string(text[:size])
+}
+
+// randomKeyLength returns the length of a random key (4 to 12)
+func randomKeyLength(source helpers.RandomSource) int {
+	return 4 + source.IntN(9)
+}
+
+// vigenereModel encrypts English-like texts using the Vigenère cipher
+// with a random key (the tableau follows the order of the alphabet,
+// e.g., the Kryptos keyed alphabet)
+type vigenereModel struct {
+	english  *markovChain
+	alphabet alphabets.Alphabet
+}
+
+func (vigenereModel) Name() string {
+	return Vigenere
+}
+
+func (m vigenereModel) Generate(source helpers.RandomSource, size int) string {
+	plaintext := m.english.Generate(source, size)
+
+	key := make([]int, randomKeyLength(source))
+	for i := range key {
+		key[i] = source.IntN(m.alphabet.Len())
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+
+	for i, letter := range plaintext {
+		sb.WriteRune(m.alphabet.Shift(letter, key[i%len(key)]))
+	}
+
+	return sb.String()
+}
+
+// transpositionModel encrypts English-like texts using a columnar
+// transposition with a random key
+type transpositionModel struct {
+	english *markovChain
+}
+
+func (transpositionModel) Name() string {
+	return Transposition
+}
+
+func (m transpositionModel) Generate(source helpers.RandomSource, size int) string {
+	plaintext := m.english.Generate(source, size)
+
+	// random order of the columns
+	columns := make([]int, randomKeyLength(source))
+	for i := range columns {
+		columns[i] = i
+	}
+	for i := len(columns) - 1; i > 0; i-- {
+		j := source.IntN(i + 1)
+		columns[i], columns[j] = columns[j], columns[i]
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+
+	for _, column := range columns {
+		for i := column; i < len(plaintext); i += len(columns) {
+			sb.WriteByte(plaintext[i])
+		}
+	}
+
+	return sb.String()
+}
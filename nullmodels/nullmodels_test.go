@@ -0,0 +1,157 @@
+package nullmodels
+
+import (
+	"sort"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+const k4 = "OBKR" +
+	"UOXOGHULBSOLIFBBWFLRVQQPRNGKSSO" +
+	"TWTQSJQSSEKZZWATJKLUDIAWINFBNYP" +
+	"VTTMZFPKWGDKZXTJCDIGKUHUAUEKCAR"
+
+func sortLetters(s string) string {
+	letters := []rune(s)
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i] < letters[j]
+	})
+	return string(letters)
+}
+
+func TestNullModels(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			model, err := Get(name, k4, "", alphabets.GetLatin())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if model.Name() != name {
+				t.Errorf("name — expected: %s, got: %s", name, model.Name())
+			}
+
+			pseudoK4 := model.Generate(helpers.GetSeededSource(42, 1), len(k4))
+			if len(pseudoK4) != len(k4) {
+				t.Errorf("length — expected: %d, got: %d", len(k4), len(pseudoK4))
+			}
+
+			for _, c := range pseudoK4 {
+				if c < 'A' || c > 'Z' {
+					t.Errorf("unexpected character: %q", c)
+				}
+			}
+
+			// seeded generations are reproducible
+			if pseudoK4 != model.Generate(helpers.GetSeededSource(42, 1), len(k4)) {
+				t.Error("expected a reproducible generation")
+			}
+		})
+	}
+}
+
+func TestShuffleModel(t *testing.T) {
+	model, err := Get(Shuffle, k4, "", alphabets.GetLatin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pseudoK4 := model.Generate(helpers.GetSeededSource(42, 1), len(k4))
+	if pseudoK4 == k4 {
+		t.Error("expected a shuffled K4")
+	}
+
+	// the letters of K4 are preserved
+	if sortLetters(pseudoK4) != sortLetters(k4) {
+		t.Errorf("expected the letters of K4, got: %s", pseudoK4)
+	}
+}
+
+func TestTranspositionModel(t *testing.T) {
+	transposition, err := Get(Transposition, "", "", alphabets.GetLatin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markov, err := Get(Markov, "", "", alphabets.GetLatin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a transposition preserves the letters of the plaintext
+	pseudoK4 := transposition.Generate(helpers.GetSeededSource(42, 1), len(k4))
+	plaintext := markov.Generate(helpers.GetSeededSource(42, 1), len(k4))
+
+	if sortLetters(pseudoK4) != sortLetters(plaintext) {
+		t.Errorf("expected the letters of %s, got: %s", plaintext, pseudoK4)
+	}
+}
+
+func TestMarkovModelCorpus(t *testing.T) {
+	model, err := Get(Markov, "", "abab abab", alphabets.GetLatin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the only transitions are AB → A and BA → B
+	pseudoK4 := model.Generate(helpers.GetSeededSource(42, 1), 10)
+	if pseudoK4 != "ABABABABAB" && pseudoK4 != "BABABABABA" {
+		t.Errorf("unexpected generation: %s", pseudoK4)
+	}
+
+	if _, err := Get(Markov, "", "AB", alphabets.GetLatin()); err == nil {
+		t.Error("expected an error for a too short corpus")
+	}
+
+	if _, err := Get("gaussian", "", "", alphabets.GetLatin()); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+}
+
+func TestNullModelsAlphabets(t *testing.T) {
+	latin25, _ := alphabets.Get(alphabets.Latin25)
+	kryptos, _ := alphabets.Get(alphabets.Kryptos)
+	digits, _ := alphabets.Get(alphabets.Digits)
+
+	type test struct {
+		name          string
+		alphabet      alphabets.Alphabet
+		expectedError bool
+	}
+
+	var tests []test
+	for _, name := range Names {
+		tests = append(tests,
+			test{name: name, alphabet: latin25},
+			test{name: name, alphabet: kryptos},
+			// English-based models and the shuffle of K4
+			// cannot be written with digits
+			test{name: name, alphabet: digits, expectedError: name != Uniform},
+		)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name+"/"+tc.alphabet.Name(), func(t *testing.T) {
+			model, err := Get(tc.name, k4, "", tc.alphabet)
+			if tc.expectedError != (err != nil) {
+				t.Fatalf("error — expected: %t, got: %v", tc.expectedError, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			pseudoK4 := model.Generate(helpers.GetSeededSource(42, 1), len(k4))
+			if length := utf8.RuneCountInString(pseudoK4); length != len(k4) {
+				t.Errorf("length — expected: %d, got: %d", len(k4), length)
+			}
+
+			if err := tc.alphabet.Validate(pseudoK4); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/charts"
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/groups"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// analysisFlags are the options shared by the analyses and the simulations
+type analysisFlags struct {
+	workers       *int
+	separators    *string
+	combinations  *string
+	minSimilarity *float64
+	substitutions *int
+	format        *string
+	alphabet      *string
+	svg           *string
+
+	knownCollections *int
+}
+
+func addAnalysisFlags(flags *flag.FlagSet) *analysisFlags {
+	return &analysisFlags{
+		workers: flags.Int(
+			"workers",
+			20,
+			"number of workers to process the analysis in parallel",
+		),
+		separators: flags.String(
+			"separators",
+			"",
+			"comma-separated sets of letters acting as separators at once (e.g., WX,QZ)",
+		),
+		combinations: flags.String(
+			"combinations",
+			"",
+			"analyze all the sets of separators of the given comma-separated sizes "+
+				fmt.Sprintf("(e.g., 2,3; at most %d sets)", maxSeparatorSets),
+		),
+		minSimilarity: flags.Float64(
+			"min-similarity",
+			1,
+			"minimum similarity between the letter frequency distribution shapes "+
+				"of the groups, greater than 0 and up to 1 "+
+				"(below 1, groups of different lengths are compared)",
+		),
+		substitutions: flags.Int(
+			"substitutions",
+			0,
+			"number of candidate letter substitutions to print per matching group "+
+				"(0: disabled; text format only)",
+		),
+		format: flags.String(
+			"format",
+			helpers.FormatText,
+			"output format: text, json, or ndjson",
+		),
+		svg: flags.String(
+			"svg",
+			"",
+			"directory in which each matching collection is drawn as an SVG image: "+
+				"the colored ciphertext and the letter frequency bar charts of its groups",
+		),
+		knownCollections: flags.Int(
+			"max-known-collections",
+			analyzer.DefaultKnownCollections,
+			"maximum number of collections kept in memory, shared by all the workers, "+
+				"to skip the ones already processed (0: unlimited)",
+		),
+		alphabet: flags.String(
+			"alphabet",
+			alphabets.Latin,
+			"alphabet of the ciphertexts: "+strings.Join(alphabets.Names, ", ")+
+				", or its symbols (e.g., 0123456789ABCDEF)",
+		),
+	}
+}
+
+// getAlphabet returns the alphabet of the ciphertexts
+func (f *analysisFlags) getAlphabet() (alphabets.Alphabet, error) {
+	return alphabets.Parse(*f.alphabet)
+}
+
+// maxSeparatorSets is the maximum number of sets of separators analyzed
+// at once (e.g., all the pairs and triples of A–Z: 325 + 2600 sets)
+const maxSeparatorSets = 10000
+
+// validateMinSimilarity ensures that a minimum similarity is greater than 0
+// and up to 1 (1: identical shapes)
+func validateMinSimilarity(minSimilarity float64) error {
+	if minSimilarity <= 0 || minSimilarity > 1 {
+		return fmt.Errorf(
+			"invalid minimum similarity: %g (expected: greater than 0 and up to 1)",
+			minSimilarity,
+		)
+	}
+
+	return nil
+}
+
+// getSeparatorSets returns the sets of separators to analyze:
+// explicit sets (e.g., "WX,QZ") or all the combinations of the given
+// sizes (e.g., "2,3"), defaulting to each symbol of the alphabet on its own
+func getSeparatorSets(
+	separators, combinations string,
+	alphabet alphabets.Alphabet,
+) ([][]rune, error) {
+	var separatorSets [][]rune
+
+	for _, set := range strings.Split(separators, ",") {
+		var separatorSet []rune
+		for _, c := range strings.TrimSpace(set) {
+			symbol, ok := alphabet.Normalize(c)
+			if !ok {
+				return nil, fmt.Errorf("invalid separator %q: not in the %s alphabet",
+					c,
+					alphabet.Name(),
+				)
+			}
+			separatorSet = append(separatorSet, symbol)
+		}
+
+		if len(separatorSet) > 0 {
+			separatorSets = append(separatorSets, separatorSet)
+		}
+	}
+
+	if uint(len(separatorSets)) > maxSeparatorSets {
+		return nil, fmt.Errorf(
+			"too many sets of separators: %d explicit sets (maximum: %d sets)",
+			len(separatorSets),
+			maxSeparatorSets,
+		)
+	}
+
+	for _, size := range strings.Split(combinations, ",") {
+		if size = strings.TrimSpace(size); size == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 || n >= alphabet.Len() {
+			return nil, fmt.Errorf("invalid combinations size: %q", size)
+		}
+
+		// the combinations are counted before being generated
+		count := helpers.CountCombinations(alphabet.Len(), n)
+		if count > maxSeparatorSets-uint(len(separatorSets)) {
+			return nil, fmt.Errorf(
+				"too many sets of separators: %d combinations of size %d (maximum: %d sets)",
+				count,
+				n,
+				maxSeparatorSets,
+			)
+		}
+
+		separatorSets = append(separatorSets, analyzer.GetSeparatorSets(alphabet, n)...)
+	}
+
+	if len(separatorSets) == 0 {
+		separatorSets = analyzer.GetSeparatorSets(alphabet, 1)
+	}
+
+	return separatorSets, nil
+}
+
+// printSubstitutions prints the candidate rewrites of the groups
+// of a collection in the alphabet of its first group
+func printSubstitutions(collection *groups.Collection, limit int) {
+	reference := collection.Groups[0]
+
+	for j, group := range collection.Groups[1:] {
+		classes, err := frequencies.DeriveRankClasses(group, reference)
+		if err != nil {
+			fmt.Printf("  Group %d: %s\n", j+2, err.Error())
+			continue
+		}
+
+		helpers.PrintSubstitutions(
+			group,
+			j+1,
+			classes,
+			frequencies.GenerateSubstitutions(classes, limit),
+		)
+	}
+}
+
+// pipeline analyzes jobs, records their results, and prints them
+type pipeline struct {
+	options            analyzer.Options
+	format             string
+	printer            helpers.Printer
+	substitutionsLimit int
+	recorder           *helpers.StatisticsRecorder
+
+	// seed of the pseudo-K4s generator, if any
+	seed *uint64
+
+	// rank the results by similarity once the analysis is completed
+	rank bool
+
+	// query of the signatures index printed once the analysis
+	// is completed in place of the results, if any
+	query *signaturesQuery
+
+	// do not print the results (only the statistics are recorded)
+	quiet bool
+
+	// directory of the SVG images of the results, if any,
+	// and last index used per name
+	svgDirectory string
+	svgCounts    map[string]int
+
+	// number of jobs whose groups of different lengths have not been
+	// compared, their segments being too many
+	tooManySegments uint
+
+	// observer of the simulation, if monitored,
+	// and its progress, if displayed
+	monitor *monitor
+	status  *statusDisplay
+}
+
+func newPipeline(
+	flags *analysisFlags,
+	alphabet alphabets.Alphabet,
+	separatorSets [][]rune,
+	recorder *helpers.StatisticsRecorder,
+) (*pipeline, error) {
+	printer, err := helpers.GetPrinter(*flags.format, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateMinSimilarity(*flags.minSimilarity); err != nil {
+		return nil, err
+	}
+
+	if *flags.knownCollections < 0 {
+		return nil, fmt.Errorf(
+			"invalid maximum number of known collections: %d",
+			*flags.knownCollections,
+		)
+	}
+
+	p := &pipeline{
+		options: analyzer.Options{
+			Workers:       *flags.workers,
+			SeparatorSets: separatorSets,
+			MinSimilarity: *flags.minSimilarity,
+			Alphabet:      alphabet,
+			Generator:     groups.GetGroupsGeneratorWithLimit(*flags.knownCollections),
+		},
+		format:             *flags.format,
+		printer:            printer,
+		substitutionsLimit: *flags.substitutions,
+		recorder:           recorder,
+		svgDirectory:       *flags.svg,
+		svgCounts:          make(map[string]int),
+	}
+
+	if p.svgDirectory != "" {
+		if err := os.MkdirAll(p.svgDirectory, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	// substitutions are only printed along with the text output
+	if p.format != helpers.FormatText {
+		p.substitutionsLimit = 0
+	}
+
+	return p, nil
+}
+
+func (p *pipeline) print(result analyzer.Result) {
+	if p.status != nil {
+		p.status.suspend(func() { p.printResult(result) })
+		return
+	}
+
+	p.printResult(result)
+}
+
+func (p *pipeline) printResult(result analyzer.Result) {
+	record := result.Record()
+
+	// the seed and the simulation id identify a pseudo-K4
+	if result.SimulationId != 0 {
+		record.Seed = p.seed
+	}
+
+	if err := p.printer.Print(record); err != nil {
+		fmt.Fprintf(os.Stderr, "error when printing: %s\n", err.Error())
+	}
+
+	// rewrite the groups in the alphabet of the first group
+	if p.substitutionsLimit > 0 && result.IdenticalShapes {
+		printSubstitutions(result.Collection, p.substitutionsLimit)
+	}
+}
+
+// warnTooManySegments reports a job whose groups of different lengths
+// have not been compared
+func (p *pipeline) warnTooManySegments(result analyzer.Result) {
+	warn := func() {
+		fmt.Fprintf(os.Stderr,
+			"too many segments with the separators %q (maximum: %d): "+
+				"only identical shapes are searched\n",
+			string(result.Separators),
+			analyzer.MaxSimilaritySegments,
+		)
+	}
+
+	if p.status != nil {
+		p.status.suspend(warn)
+		return
+	}
+
+	warn()
+}
+
+// fileSafe returns a string usable in a file name
+// (e.g., the symbols of custom alphabets are written as code points)
+func fileSafe(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c < utf8.RuneSelf && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-') {
+			b.WriteRune(c)
+		} else {
+			fmt.Fprintf(&b, "U%04X", c)
+		}
+	}
+	return b.String()
+}
+
+// svgName returns the name of the SVG images of a result, without their
+// index (e.g., "k4_W" for "k4_W_1.svg", "sim42_K" for "sim42_K_1.svg")
+func svgName(result analyzer.Result) string {
+	var name string
+	switch {
+	case result.InputId != "":
+		name = fileSafe(result.InputId)
+	case result.SimulationId != 0:
+		name = fmt.Sprintf("sim%d", result.SimulationId)
+	case result.Ciphertext == k4:
+		name = "k4"
+	default:
+		name = "ciphertext"
+	}
+	return name + "_" + fileSafe(string(result.Separators))
+}
+
+// createSVG creates the file of the next SVG image of a result
+// (the images of the previous runs in the directory are not overwritten:
+// the next free index is used)
+func (p *pipeline) createSVG(result analyzer.Result) (*os.File, string, error) {
+	name := svgName(result)
+
+	for {
+		p.svgCounts[name]++
+		path := filepath.Join(
+			p.svgDirectory,
+			fmt.Sprintf("%s_%d.svg", name, p.svgCounts[name]),
+		)
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+
+		return file, path, err
+	}
+}
+
+// draw writes the SVG image of a result
+func (p *pipeline) draw(result analyzer.Result) {
+	file, path, err := p.createSVG(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error when drawing: %s\n", err.Error())
+		return
+	}
+
+	err = charts.RenderSVG(file, result.Record(), charts.Options{})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error when drawing %s: %s\n", path, err.Error())
+	}
+}
+
+// run analyzes the jobs sent by produce until it returns
+func (p *pipeline) run(produce func(ctx context.Context, jobs chan<- analyzer.Job)) int {
+	// ^C stops generating jobs: the jobs already queued are processed
+	// so that the statistics remain consistent
+	ctx, stopFunc := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGINT,
+		syscall.SIGTERM,
+	)
+	defer stopFunc()
+
+	// a second ^C terminates the analysis immediately
+	workersCtx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	go func() {
+		<-ctx.Done()
+		stopFunc()
+
+		terminate := make(chan os.Signal, 1)
+		signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+		<-terminate
+		cancelFunc()
+	}()
+
+	if p.query != nil {
+		p.options.Index = analyzer.NewIndex()
+	}
+
+	jobs := make(chan analyzer.Job, 1000)
+
+	if p.monitor != nil {
+		p.options.Activity = p.monitor.activity
+		p.monitor.begin(jobs)
+	}
+
+	// the display is erased once the analysis is completed
+	stopStatus := func() {}
+	if p.status != nil {
+		statusCtx, cancelStatus := context.WithCancel(context.Background())
+		defer cancelStatus()
+
+		statusDone := make(chan struct{})
+		go func() {
+			defer close(statusDone)
+			p.status.run(statusCtx)
+		}()
+
+		stopStatus = func() {
+			cancelStatus()
+			<-statusDone
+		}
+	}
+
+	go func() {
+		// signal that all jobs have been sent
+		defer close(jobs)
+		produce(ctx, jobs)
+	}()
+
+	var rankedResults []analyzer.Result
+
+	// analyze the collections to identify groups with
+	// the same letters frequency shapes
+	for result := range analyzer.Run(workersCtx, jobs, p.options) {
+		// all the results of the job have been recorded
+		if result.JobCompleted {
+			p.recorder.CompleteJob(result.SimulationId)
+			continue
+		}
+
+		// only identical shapes have been searched
+		if result.TooManySegments {
+			p.tooManySegments++
+			if !p.quiet {
+				p.warnTooManySegments(result)
+			}
+			continue
+		}
+
+		// only identical shapes are taken into account in the statistics
+		if result.IdenticalShapes {
+			p.recorder.Record(
+				result.SimulationId,
+				result.Ciphertext,
+				result.Collection.Groups,
+			)
+		}
+
+		if p.svgDirectory != "" && p.query == nil {
+			p.draw(result)
+		}
+
+		switch {
+		case p.query != nil:
+			// the results are only indexed
+		case p.quiet:
+			// the results are only recorded
+		case p.rank:
+			rankedResults = append(rankedResults, result)
+		default:
+			p.print(result)
+		}
+	}
+
+	stopStatus()
+
+	analyzer.Rank(rankedResults)
+	for _, result := range rankedResults {
+		p.print(result)
+	}
+
+	p.recorder.Save()
+
+	exitCode := exitSuccess
+
+	closePrinter := p.printer.Close
+	if p.query != nil {
+		closePrinter = func() error {
+			return printSignatures(
+				os.Stdout,
+				p.format,
+				p.query.getRecords(p.options.Index),
+			)
+		}
+	}
+
+	if err := closePrinter(); err != nil {
+		fmt.Fprintf(os.Stderr, "error when printing: %s\n", err.Error())
+		exitCode = exitFailure
+	}
+
+	// keep the standard output parsable when a structured format is used
+	summary := os.Stdout
+	if p.format != helpers.FormatText {
+		summary = os.Stderr
+	}
+
+	fmt.Fprintln(summary, "Analysis Completed.")
+	fmt.Fprintf(summary, "Same shapes:\t%d\n", p.recorder.GetSameShapesCount())
+	fmt.Fprintf(summary, "K4-like:\t%d\n", p.recorder.GetK4LikeCount())
+	fmt.Fprintf(summary, "Duplicates skipped:\t%d\n", p.options.Generator.GetDuplicatesCount())
+
+	// jobs whose groups of different lengths have not been compared
+	if p.tooManySegments > 0 {
+		fmt.Fprintf(summary, "Too many segments:\t%d\n", p.tooManySegments)
+	}
+
+	return exitCode
+}
+
+// sendJobs sends the jobs analyzing a ciphertext
+// and returns false if the analysis has been stopped
+func sendJobs(ctx context.Context, jobs chan<- analyzer.Job, newJobs []analyzer.Job) bool {
+	for _, job := range newJobs {
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
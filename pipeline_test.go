@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/analyzer"
+)
+
+func TestCreateSVG(t *testing.T) {
+	directory := t.TempDir()
+
+	// image of a previous run
+	previous := filepath.Join(directory, "k4_W_1.svg")
+	if err := os.WriteFile(previous, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &pipeline{svgDirectory: directory, svgCounts: make(map[string]int)}
+	result := analyzer.Result{Job: analyzer.Job{Ciphertext: k4, Separators: []rune("W")}}
+
+	for _, expected := range []string{"k4_W_2.svg", "k4_W_3.svg"} {
+		file, path, err := p.createSVG(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+
+		if filepath.Base(path) != expected {
+			t.Errorf("expected: %s, got: %s", expected, filepath.Base(path))
+		}
+	}
+
+	if content, err := os.ReadFile(previous); err != nil || string(content) != "previous" {
+		t.Errorf("expected the previous image to be kept, got: %q (%v)", content, err)
+	}
+}
+
+func TestGetSeparatorSets(t *testing.T) {
+	alphabet := alphabets.GetLatin()
+
+	// more explicit sets than allowed (the check must not underflow)
+	tooMany := strings.TrimSuffix(strings.Repeat("AB,", maxSeparatorSets+1), ",")
+
+	testCases := []struct {
+		name         string
+		separators   string
+		combinations string
+		expected     int
+		failing      bool
+	}{
+		{"default", "", "", 26, false},
+		{"explicit", "WX,QZ", "", 2, false},
+		{"combinations", "WX", "2", 1 + 325, false},
+		{"too many combinations", "", "13", 0, true},
+		{"too many explicit sets", tooMany, "", 0, true},
+		{"too many explicit sets with combinations", tooMany, "1", 0, true},
+	}
+
+	for _, tc := range testCases {
+		separatorSets, err := getSeparatorSets(tc.separators, tc.combinations, alphabet)
+		if tc.failing {
+			if err == nil || !strings.Contains(err.Error(), "too many sets of separators") {
+				t.Errorf("%s: expected a too many sets error, got: %v", tc.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		} else if len(separatorSets) != tc.expected {
+			t.Errorf("%s: expected %d sets, got %d", tc.name, tc.expected, len(separatorSets))
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// monitor observes a running simulation
+// (e.g., for the status display and the metrics endpoint)
+type monitor struct {
+	mu sync.Mutex
+
+	recorder *helpers.StatisticsRecorder
+	activity *analyzer.Activity
+	jobs     chan analyzer.Job
+
+	// number of pseudo-K4s after which the simulation stops
+	// (0: no target)
+	target uint
+
+	// start of the run (the resumed pseudo-K4s are not part of its throughput)
+	start      time.Time
+	startCount uint
+}
+
+func newMonitor(recorder *helpers.StatisticsRecorder, target uint) *monitor {
+	return &monitor{
+		recorder: recorder,
+		activity: analyzer.NewActivity(),
+		target:   target,
+	}
+}
+
+// begin starts observing the jobs of a run
+func (m *monitor) begin(jobs chan analyzer.Job) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs = jobs
+	m.start = time.Now()
+	m.startCount = m.recorder.Snapshot().SimulationsCount
+}
+
+// progress returns the current state of the simulation
+// (the rate is the average one since the start of the run)
+func (m *monitor) progress(now time.Time) helpers.Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statistics := m.recorder.Snapshot()
+
+	progress := helpers.Progress{
+		Statistics:    statistics,
+		Target:        m.target,
+		QueuedJobs:    len(m.jobs),
+		QueueCapacity: cap(m.jobs),
+		BusyWorkers:   m.activity.BusyWorkers(),
+		Workers:       m.activity.Workers(),
+	}
+
+	// the run has not started yet
+	if m.start.IsZero() {
+		return progress
+	}
+
+	progress.Elapsed = now.Sub(m.start)
+
+	count := statistics.SimulationsCount
+	if seconds := progress.Elapsed.Seconds(); seconds > 0 && count >= m.startCount {
+		progress.AverageRate = float64(count-m.startCount) / seconds
+	}
+	progress.Rate = progress.AverageRate
+
+	return progress
+}
+
+// statusDisplay regularly writes the progress of a simulation: redrawn in
+// place on a terminal, one line per refresh otherwise (e.g., in a log file)
+type statusDisplay struct {
+	mu sync.Mutex
+
+	w           *os.File
+	interactive bool
+	interval    time.Duration
+	monitor     *monitor
+
+	// number of lines currently drawn (interactive display)
+	lines int
+
+	// last refresh, to compute the current rate
+	previous      time.Time
+	previousCount uint
+}
+
+// isTerminal identifies whether a file is a terminal or not
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func newStatusDisplay(interval time.Duration, m *monitor) *statusDisplay {
+	return &statusDisplay{
+		w:           os.Stderr,
+		interactive: isTerminal(os.Stderr),
+		interval:    interval,
+		monitor:     m,
+	}
+}
+
+// run refreshes the display until the context is done,
+// then erases it
+func (d *statusDisplay) run(ctx context.Context) {
+	d.mu.Lock()
+	d.previous = time.Now()
+	d.previousCount = d.monitor.progress(d.previous).Statistics.SimulationsCount
+	d.mu.Unlock()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.refresh()
+		case <-ctx.Done():
+			d.suspend(func() {})
+			return
+		}
+	}
+}
+
+func (d *statusDisplay) refresh() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	progress := d.monitor.progress(now)
+
+	// current rate: since the last refresh
+	count := progress.Statistics.SimulationsCount
+	if seconds := now.Sub(d.previous).Seconds(); seconds > 0 && count >= d.previousCount {
+		progress.Rate = float64(count-d.previousCount) / seconds
+	}
+	d.previous, d.previousCount = now, count
+
+	lines := helpers.FormatProgress(progress)
+
+	if !d.interactive {
+		fields := make([]string, len(lines))
+		for i, line := range lines {
+			fields[i] = strings.Join(strings.Fields(line), " ")
+		}
+		fmt.Fprintln(d.w, strings.Join(fields, " | "))
+		return
+	}
+
+	d.erase()
+	for _, line := range lines {
+		// the tabs are expanded so that each line fits on one row
+		fmt.Fprintf(d.w, "%s\033[K\n", strings.ReplaceAll(line, "\t", "  "))
+	}
+	d.lines = len(lines)
+}
+
+// erase erases the lines drawn, if any
+func (d *statusDisplay) erase() {
+	if d.lines > 0 {
+		fmt.Fprintf(d.w, "\033[%dF\033[J", d.lines)
+		d.lines = 0
+	}
+}
+
+// suspend erases the display while printing
+// (the next refresh draws it again)
+func (d *statusDisplay) suspend(print func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.erase()
+	print()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// runReport prints the statistics of one or several simulations
+// (statistics or checkpoint files)
+func runReport(args []string) int {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(),
+			"Usage: %s report [options] [statistics or checkpoint files]\n\n"+
+				"Print the statistics of one or several simulations "+
+				"(default: checkpoint.json).\n\n",
+			os.Args[0],
+		)
+		flags.PrintDefaults()
+	}
+
+	format := flags.String(
+		"format",
+		helpers.FormatText,
+		"output format: text or json",
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *format != helpers.FormatText && *format != helpers.FormatJSON {
+		fmt.Fprintf(os.Stderr, "unsupported format: %q\n", *format)
+		return exitUsage
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"checkpoint.json"}
+	}
+
+	var checkpoints []*helpers.Checkpoint
+	for _, path := range paths {
+		checkpoint, err := helpers.LoadStatistics(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	statistics := checkpoints[0]
+	if len(checkpoints) > 1 {
+		merged, err := helpers.MergeStatistics(checkpoints)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		statistics = merged
+	}
+
+	if *format == helpers.FormatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(statistics); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		return exitSuccess
+	}
+
+	fmt.Print(helpers.FormatStatistics(statistics))
+
+	return exitSuccess
+}
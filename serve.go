@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// maximum size of the body of an analysis request
+const maxRequestSize = 1 << 20
+
+// limits of the work of an analysis request (the number of partitions
+// grows quickly with the number of segments, even more so when groups
+// of different lengths are compared)
+const (
+	// sets of separators (e.g., all the pairs and triples of A–Z)
+	maxRequestSeparatorSets = 3000
+
+	// segments produced by a set of separators (the partitions of 13
+	// segments into groups of the same length are analyzed in about 2s,
+	// but 14 segments can take 10s; see also analyzer.MaxSimilaritySegments
+	// when groups of different lengths are compared)
+	maxRequestSegments = 13
+
+	// collections returned, the most similar first
+	maxRequestResults = 1000
+)
+
+// default duration after which an analysis request is abandoned
+const defaultRequestTimeout = 30 * time.Second
+
+// analysisRequest is the body of an analysis request
+// (the fields mirror the options of the analyze command)
+type analysisRequest struct {
+	Ciphertext string `json:"ciphertext"`
+
+	// alphabet of the ciphertext (default: latin)
+	Alphabet string `json:"alphabet"`
+
+	// comma-separated sets of separators (e.g., "WX,QZ"), and sizes of
+	// the sets of separators to combine (e.g., "2,3")
+	// (default: each symbol of the alphabet on its own)
+	Separators   string `json:"separators"`
+	Combinations string `json:"combinations"`
+
+	// minimum number of symbols of each segment of the collections
+	// returned (0: no minimum)
+	MinSegmentLength int `json:"min_segment_len"`
+
+	// minimum similarity between the letter frequency distribution shapes
+	// of the groups, greater than 0 and up to 1
+	// (default: 1, i.e., identical shapes)
+	MinSimilarity *float64 `json:"min_similarity"`
+
+	// drop the invalid characters instead of rejecting the ciphertext
+	Lenient bool `json:"lenient"`
+
+	// locate the segments in the ciphertext as posted as well
+	// (original_spans of the groups)
+	OriginalPositions bool `json:"original_positions"`
+}
+
+// droppedRecord is an invalid character dropped in lenient mode
+type droppedRecord struct {
+	Character string `json:"character"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+}
+
+// analysisResponse is the body of the response to an analysis request
+type analysisResponse struct {
+	// normalized ciphertext analyzed
+	Ciphertext string `json:"ciphertext"`
+
+	Dropped []droppedRecord  `json:"dropped,omitempty"`
+	Results []helpers.Record `json:"results"`
+
+	// more collections matched than returned (see maxRequestResults)
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Fprintf(os.Stderr, "error when writing response: %s\n", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// analysisHandler analyzes the ciphertexts posted as JSON
+// (the analysis stops as soon as the request is canceled,
+// e.g., when the client disconnects, or once it times out)
+func analysisHandler(workers int, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request analysisRequest
+
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+
+		minSimilarity := 1.0
+		if request.MinSimilarity != nil {
+			minSimilarity = *request.MinSimilarity
+		}
+
+		if err := validateMinSimilarity(minSimilarity); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if request.MinSegmentLength < 0 {
+			writeError(w, http.StatusBadRequest,
+				fmt.Errorf("invalid min_segment_len: %d", request.MinSegmentLength),
+			)
+			return
+		}
+
+		if request.Alphabet == "" {
+			request.Alphabet = alphabets.Latin
+		}
+
+		alphabet, err := alphabets.Parse(request.Alphabet)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		separatorSets, err := getSeparatorSets(
+			request.Separators,
+			request.Combinations,
+			alphabet,
+		)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if len(separatorSets) > maxRequestSeparatorSets {
+			writeError(w, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("too many sets of separators: %d (maximum: %d)",
+					len(separatorSets),
+					maxRequestSeparatorSets,
+				),
+			)
+			return
+		}
+
+		normalized, err := helpers.Normalize(request.Ciphertext, helpers.NormalizeOptions{
+			Lenient:       request.Lenient,
+			KeepPositions: request.OriginalPositions,
+			Alphabet:      alphabet,
+		})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		maxSegments := maxRequestSegments
+		if minSimilarity < 1 {
+			maxSegments = analyzer.MaxSimilaritySegments
+		}
+
+		for _, separators := range separatorSets {
+			segments := helpers.Split(normalized.Ciphertext, separators...)
+			if len(segments) > maxSegments {
+				writeError(w, http.StatusRequestEntityTooLarge,
+					fmt.Errorf("too many segments: %d with the separators %q (maximum: %d)",
+						len(segments),
+						string(separators),
+						maxSegments,
+					),
+				)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		results, err := analyzer.Analyze(ctx, normalized.Ciphertext, analyzer.Options{
+			Workers:       workers,
+			SeparatorSets: separatorSets,
+			MinSimilarity: minSimilarity,
+			Alphabet:      alphabet,
+		})
+
+		switch {
+		case r.Context().Err() != nil:
+			// the client is gone: nobody reads the response
+			return
+		case ctx.Err() != nil:
+			writeError(w, http.StatusServiceUnavailable,
+				fmt.Errorf("analysis timed out after %s", timeout),
+			)
+			return
+		case err != nil:
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		response := analysisResponse{
+			Ciphertext: normalized.Ciphertext,
+			Results:    []helpers.Record{},
+		}
+
+		for _, invalid := range normalized.Dropped {
+			response.Dropped = append(response.Dropped, droppedRecord{
+				Character: string(invalid.Character),
+				Line:      invalid.Position.Line,
+				Column:    invalid.Position.Column,
+			})
+		}
+
+		criteria := helpers.Criteria{MinSegmentLength: request.MinSegmentLength}
+		for _, result := range results {
+			if !criteria.Match(result.Collection.Groups, result.Classification) {
+				continue
+			}
+
+			if len(response.Results) == maxRequestResults {
+				response.Truncated = true
+				break
+			}
+
+			record := result.Record()
+			record.SetOriginalSpans(normalized.Positions)
+			response.Results = append(response.Results, record)
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// runServe exposes the analysis over HTTP
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(),
+			"Usage: %s serve [options]\n\n"+
+				"Expose the analysis over HTTP: POST /analyze a JSON object "+
+				"(ciphertext, alphabet, separators, combinations, min_segment_len, "+
+				"min_similarity, lenient, original_positions) to get the matching "+
+				"collections as JSON.\n\n",
+			os.Args[0],
+		)
+		flags.PrintDefaults()
+	}
+
+	addr := flags.String(
+		"addr",
+		"localhost:8097",
+		"address on which the analysis is exposed",
+	)
+	workers := flags.Int(
+		"workers",
+		20,
+		"number of workers to process each analysis in parallel",
+	)
+	timeout := flags.Duration(
+		"timeout",
+		defaultRequestTimeout,
+		"duration after which an analysis is abandoned (503)",
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *timeout <= 0 {
+		fmt.Fprintln(os.Stderr, "invalid timeout: must be positive")
+		return exitUsage
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitFailure
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /analyze", analysisHandler(*workers, *timeout))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// ^C stops accepting requests and cancels the ongoing analyses
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-stop
+		server.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on http://%s\n", listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitFailure
+	}
+
+	return exitSuccess
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postAnalysis(ctx context.Context, body string) *httptest.ResponseRecorder {
+	return postAnalysisWithTimeout(ctx, body, defaultRequestTimeout)
+}
+
+func postAnalysisWithTimeout(
+	ctx context.Context,
+	body string,
+	timeout time.Duration,
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(
+		http.MethodPost,
+		"/analyze",
+		strings.NewReader(body),
+	).WithContext(ctx)
+
+	recorder := httptest.NewRecorder()
+	analysisHandler(4, timeout).ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// manyPartitions is split by Z into as many segments as allowed, of 1 and 2
+// letters: tens of thousands of partitions into groups of the same length,
+// all with identical shapes
+var manyPartitions = strings.Join([]string{
+	"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "LM", "NO",
+}, "Z")
+
+func TestAnalysisHandler(t *testing.T) {
+	recorder := postAnalysis(
+		context.Background(),
+		`{"ciphertext": "`+k4+`", "min_segment_len": 3}`,
+	)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status — expected: %d, got: %d (%s)",
+			http.StatusOK,
+			recorder.Code,
+			recorder.Body,
+		)
+	}
+
+	var response analysisResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Ciphertext != k4 || len(response.Results) != 1 {
+		t.Fatalf("expected the groups of K4, got: %+v", response)
+	}
+
+	if string(response.Results[0].Separators) != "W" {
+		t.Errorf("separators — expected: W, got: %s", response.Results[0].Separators)
+	}
+}
+
+func TestAnalysisHandlerOriginalPositions(t *testing.T) {
+	// K4 laid out on two lines, the first one ending with the first segment
+	ciphertext := k4[:20] + `\n` + k4[20:]
+
+	recorder := postAnalysis(
+		context.Background(),
+		`{"ciphertext": "`+ciphertext+`", "separators": "W", "original_positions": true}`,
+	)
+
+	var response analysisResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Results) != 1 {
+		t.Fatalf("expected the groups of K4, got: %+v", response)
+	}
+
+	// the segments after the line break are shifted by one character
+	for _, group := range response.Results[0].Groups {
+		for i, span := range group.Spans {
+			shift := 0
+			if span[0] >= 20 {
+				shift = 1
+			}
+
+			expected := [2]int{span[0] + shift, span[1] + shift}
+			if group.OriginalSpans[i] != expected {
+				t.Errorf("original span of %v — expected: %v, got: %v",
+					span,
+					expected,
+					group.OriginalSpans[i],
+				)
+			}
+		}
+	}
+}
+
+func TestAnalysisHandlerInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", `{"ciphertext": `, http.StatusBadRequest},
+		{"unknown field", `{"ciphertext": "ABC", "sim": true}`, http.StatusBadRequest},
+		{"empty ciphertext", `{}`, http.StatusBadRequest},
+		{"invalid character", `{"ciphertext": "K4!"}`, http.StatusBadRequest},
+		{"null similarity", `{"ciphertext": "ABC", "min_similarity": 0}`, http.StatusBadRequest},
+		{"invalid separator", `{"ciphertext": "ABC", "separators": "1"}`, http.StatusBadRequest},
+		{"too many combinations", `{"ciphertext": "ABC", "combinations": "13"}`, http.StatusBadRequest},
+		{
+			"too many sets of separators",
+			`{"ciphertext": "ABC", "alphabet": "0123456789ABCDEF", "combinations": "5"}`,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"too many segments",
+			`{"ciphertext": "` + strings.Repeat("AB", 30) + `"}`,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"too many segments to compare",
+			`{"ciphertext": "` + k4 + `", "separators": "KO", "min_similarity": 0.9}`,
+			http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := postAnalysis(context.Background(), tc.body)
+
+			if recorder.Code != tc.status {
+				t.Errorf("status — expected: %d, got: %d (%s)",
+					tc.status,
+					recorder.Code,
+					recorder.Body,
+				)
+			}
+
+			var response errorResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil ||
+				response.Error == "" {
+				t.Errorf("expected an error message, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestAnalysisHandlerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// a few seconds of work
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postAnalysis(ctx, `{"ciphertext": "`+manyPartitions+`", "separators": "Z"}`)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case recorder := <-done:
+		// the client is gone: nothing is written
+		if recorder.Body.Len() != 0 {
+			t.Errorf("expected no response, got: %s", recorder.Body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the analysis to stop once the request is canceled")
+	}
+}
+
+func TestAnalysisHandlerTimeout(t *testing.T) {
+	recorder := postAnalysisWithTimeout(
+		context.Background(),
+		`{"ciphertext": "`+manyPartitions+`", "separators": "Z"}`,
+		10*time.Millisecond,
+	)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("status — expected: %d, got: %d (%s)",
+			http.StatusServiceUnavailable,
+			recorder.Code,
+			recorder.Body,
+		)
+	}
+}
+
+func TestAnalysisHandlerTruncated(t *testing.T) {
+	// 12 segments of 2 letters: thousands of collections
+	ciphertext := strings.Join([]string{
+		"AB", "CD", "EF", "GH", "IJ", "KL", "MN", "OP", "QR", "ST", "UV", "XY",
+	}, "Z")
+
+	recorder := postAnalysis(
+		context.Background(),
+		`{"ciphertext": "`+ciphertext+`", "separators": "Z"}`,
+	)
+
+	var response analysisResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if !response.Truncated || len(response.Results) != maxRequestResults {
+		t.Errorf("expected %d results (truncated), got: %d (truncated: %t)",
+			maxRequestResults,
+			len(response.Results),
+			response.Truncated,
+		)
+	}
+}
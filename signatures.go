@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/frequencies"
+	"github.com/glethuillier/K4nundrum/helpers"
+)
+
+// indexedGroupRecord is the structured representation
+// of a group of the signatures index
+type indexedGroupRecord struct {
+	InputId      string   `json:"input_id,omitempty"`
+	Ciphertext   string   `json:"ciphertext"`
+	Separators   string   `json:"separators"`
+	SimulationId uint     `json:"simulation_id,omitempty"`
+	Segments     []string `json:"segments"`
+}
+
+// signatureRecord is the structured representation of the groups
+// having the same letter frequency distribution shape
+type signatureRecord struct {
+	Signature  string               `json:"signature"`
+	Separators []string             `json:"separators"`
+	Groups     []indexedGroupRecord `json:"groups"`
+}
+
+func newSignatureRecord(
+	signature frequencies.Signature,
+	entries []analyzer.IndexEntry,
+) signatureRecord {
+	record := signatureRecord{
+		Signature:  signature.String(),
+		Separators: []string{},
+		Groups:     make([]indexedGroupRecord, len(entries)),
+	}
+
+	for i, entry := range entries {
+		separators := string(entry.Separators)
+		if !slices.Contains(record.Separators, separators) {
+			record.Separators = append(record.Separators, separators)
+		}
+
+		record.Groups[i] = indexedGroupRecord{
+			InputId:      entry.InputId,
+			Ciphertext:   entry.Ciphertext,
+			Separators:   separators,
+			SimulationId: entry.SimulationId,
+			Segments:     entry.Group.Segments,
+		}
+	}
+	slices.Sort(record.Separators)
+
+	return record
+}
+
+// signaturesQuery is a query of the signatures index
+type signaturesQuery struct {
+	// groups having this signature, if any
+	signature *frequencies.Signature
+
+	// signatures produced by several sets of separators
+	shared bool
+}
+
+// getRecords returns the results of the query
+func (q signaturesQuery) getRecords(index *analyzer.Index) []signatureRecord {
+	records := []signatureRecord{}
+
+	if q.signature != nil {
+		if entries := index.Lookup(*q.signature); len(entries) > 0 {
+			records = append(records, newSignatureRecord(*q.signature, entries))
+		}
+	}
+
+	if q.shared {
+		for _, shared := range index.Shared() {
+			records = append(records, newSignatureRecord(shared.Signature, shared.Entries))
+		}
+	}
+
+	return records
+}
+
+// printSignatures prints the results of a query of the signatures index
+func printSignatures(w io.Writer, format string, records []signatureRecord) error {
+	switch format {
+	case helpers.FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case helpers.FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(w, "\nNo matching signature.")
+	}
+
+	for _, record := range records {
+		fmt.Fprintf(w, "\nSignature: %s\n  Separators: %s\n  Groups: %d\n\n",
+			record.Signature,
+			strings.Join(record.Separators, ", "),
+			len(record.Groups),
+		)
+
+		for _, group := range record.Groups {
+			fmt.Fprintf(w, "  %s", group.Separators)
+			if group.InputId != "" {
+				fmt.Fprintf(w, "\t# %s", group.InputId)
+			}
+			if group.SimulationId != 0 {
+				fmt.Fprintf(w, "\t#%d", group.SimulationId)
+			}
+			fmt.Fprintf(w, "\t%s\n", strings.Join(group.Segments, " "))
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	return nil
+}
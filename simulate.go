@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/glethuillier/K4nundrum/alphabets"
+	"github.com/glethuillier/K4nundrum/analyzer"
+	"github.com/glethuillier/K4nundrum/groups"
+	"github.com/glethuillier/K4nundrum/helpers"
+	"github.com/glethuillier/K4nundrum/nullmodels"
+)
+
+// generatorFlags are the options of the pseudo-K4s generator
+type generatorFlags struct {
+	nullModel *string
+	corpus    *string
+	seed      *uint64
+}
+
+func addGeneratorFlags(flags *flag.FlagSet) *generatorFlags {
+	return &generatorFlags{
+		nullModel: flags.String(
+			"null-model",
+			nullmodels.Uniform,
+			"generator of pseudo-K4s: "+strings.Join(nullmodels.Names, ", "),
+		),
+		corpus: flags.String(
+			"corpus",
+			"",
+			"text file training the Markov chain of the markov, vigenere, and "+
+				"transposition null models (default: K1–K3 plaintexts)",
+		),
+		seed: flags.Uint64(
+			"seed",
+			0,
+			"seed of the pseudo-K4s generator, to make simulations reproducible "+
+				"(default: nondeterministic generator)",
+		),
+	}
+}
+
+// getNullModel returns the generator of the pseudo-K4s
+func (f *generatorFlags) getNullModel(alphabet alphabets.Alphabet) (nullmodels.NullModel, error) {
+	var corpus string
+	if *f.corpus != "" {
+		content, err := os.ReadFile(*f.corpus)
+		if err != nil {
+			return nil, err
+		}
+		corpus = string(content)
+	}
+
+	return nullmodels.Get(*f.nullModel, k4, corpus, alphabet)
+}
+
+// getRandomSource returns the random source generating
+// the pseudo-K4 of a given simulation
+func getRandomSource(seed *uint64, simulationId uint) helpers.RandomSource {
+	if seed != nil {
+		return helpers.GetSeededSource(*seed, simulationId)
+	}
+	return helpers.GetCryptoSource()
+}
+
+// getSetFlags returns the flags explicitly set on the command line
+func getSetFlags(flags *flag.FlagSet) map[string]bool {
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	return setFlags
+}
+
+// checkResumedSettings ensures that the settings explicitly set on the
+// command line match the ones of the simulation to resume
+func checkResumedSettings(
+	resumed helpers.Settings,
+	settings helpers.Settings,
+	setFlags map[string]bool,
+) error {
+	if setFlags["seed"] &&
+		(resumed.Seed == nil || *resumed.Seed != *settings.Seed) {
+		return fmt.Errorf("cannot resume: the checkpoint uses another seed")
+	}
+
+	if setFlags["alphabet"] && resumed.AlphabetName() != settings.AlphabetName() {
+		return fmt.Errorf("cannot resume: the checkpoint uses the %q alphabet",
+			resumed.AlphabetName(),
+		)
+	}
+
+	if setFlags["null-model"] && resumed.NullModel != settings.NullModel {
+		return fmt.Errorf("cannot resume: the checkpoint uses the %q null model",
+			resumed.NullModel,
+		)
+	}
+
+	if (setFlags["separators"] || setFlags["combinations"]) &&
+		!slices.Equal(resumed.Separators, settings.Separators) {
+		return fmt.Errorf("cannot resume: the checkpoint uses other separators")
+	}
+
+	if (setFlags["metric"] || setFlags["metrics"]) &&
+		!slices.Equal(resumed.Metrics, settings.Metrics) {
+		return fmt.Errorf("cannot resume: the checkpoint uses other metrics")
+	}
+
+	if resumed.CiphertextLength != settings.CiphertextLength {
+		return fmt.Errorf("cannot resume: the checkpoint uses pseudo-K4s of %d letters",
+			resumed.CiphertextLength,
+		)
+	}
+
+	return nil
+}
+
+// checkWritable ensures that a file can be written before the simulation
+// starts (a file created for the check is removed)
+func checkWritable(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	_, err := os.Stat(path)
+	existing := err == nil
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	file.Close()
+
+	if !existing {
+		return os.Remove(path)
+	}
+
+	return nil
+}
+
+// observeK4 records the metrics met by K4, analyzed with the alphabet
+// and the separators of the simulation
+func observeK4(
+	recorder *helpers.StatisticsRecorder,
+	alphabet alphabets.Alphabet,
+	separatorSets [][]rune,
+	workers int,
+) error {
+	// K4 cannot be written in the alphabet: it meets no metric
+	normalized, err := helpers.Normalize(k4, helpers.NormalizeOptions{Alphabet: alphabet})
+	if err != nil {
+		recorder.ObserveK4(k4, nil)
+		return nil
+	}
+
+	results, err := analyzer.Analyze(context.Background(), normalized.Ciphertext, analyzer.Options{
+		Workers:       workers,
+		SeparatorSets: separatorSets,
+		Alphabet:      alphabet,
+	})
+	if err != nil {
+		return err
+	}
+
+	collections := make([][]groups.Group, len(results))
+	for i, result := range results {
+		collections[i] = result.Collection.Groups
+	}
+
+	recorder.ObserveK4(normalized.Ciphertext, collections)
+
+	return nil
+}
+
+// runSimulate analyzes random pseudo-K4s and records statistics
+func runSimulate(args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(),
+			"Usage: %s simulate [options]\n\n"+
+				"Analyze random pseudo-K4s and record statistics.\n\n",
+			os.Args[0],
+		)
+		flags.PrintDefaults()
+	}
+
+	analysis := addAnalysisFlags(flags)
+	generator := addGeneratorFlags(flags)
+	targetIntervalWidth := flags.Float64(
+		"ci-width",
+		0,
+		"stop the simulation once the 95% confidence interval of the proportion "+
+			"of K4-like pseudo-K4s is narrower than this width (e.g., 0.0001; 0: disabled)",
+	)
+	statisticsPath := flags.String(
+		"stats",
+		helpers.StatisticsFilename,
+		"file in which the human-readable statistics of the simulation are regularly saved",
+	)
+	checkpointPath := flags.String(
+		"checkpoint",
+		"checkpoint.json",
+		"file in which the state of the simulation is regularly saved",
+	)
+	resume := flags.Bool(
+		"resume",
+		false,
+		"resume the simulation saved in the checkpoint file",
+	)
+	overwrite := flags.Bool(
+		"overwrite",
+		false,
+		"start a new simulation even if the checkpoint file exists, overwriting it",
+	)
+	maxSimulations := flags.Uint(
+		"simulations",
+		0,
+		"stop the simulation once this number of pseudo-K4s have been generated, "+
+			"including the resumed ones (0: no limit)",
+	)
+	progressInterval := flags.Duration(
+		"progress",
+		0,
+		"display the progress of the simulation on the standard error at this "+
+			"interval (e.g., 1s; 0: disabled); redrawn in place on a terminal",
+	)
+	metricsAddr := flags.String(
+		"metrics-addr",
+		"",
+		"address on which the metrics of the simulation are exposed in the "+
+			"OpenMetrics text format at /metrics (e.g., localhost:9097; empty: disabled)",
+	)
+	quiet := flags.Bool(
+		"quiet",
+		false,
+		"do not print the matching groups (the statistics are still recorded)",
+	)
+	metricsPath := flags.String(
+		"metrics",
+		"",
+		"file of user-defined metrics recorded in the statistics, one per line",
+	)
+	var metrics []helpers.Metric
+	flags.Func(
+		"metric",
+		"user-defined metric recorded in the statistics, as {{name}}: {{criteria}} "+
+			"(e.g., \"Long: min_segment_len=4, groups=2, alternation=strict\"; "+
+			"criteria: "+strings.Join(helpers.CriteriaKeys, ", ")+"; repeatable)",
+		func(definition string) error {
+			metric, err := helpers.ParseMetric(definition)
+			if err != nil {
+				return err
+			}
+			metrics = append(metrics, metric)
+			return nil
+		},
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	setFlags := getSetFlags(flags)
+
+	// the seed is only used if explicitly set
+	// (0 is a valid seed)
+	var seed *uint64
+	if setFlags["seed"] {
+		seed = generator.seed
+	}
+
+	alphabet, err := analysis.getAlphabet()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	separatorSets, err := getSeparatorSets(
+		*analysis.separators,
+		*analysis.combinations,
+		alphabet,
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	// a new simulation would overwrite the saved one
+	if !*resume && !*overwrite && *checkpointPath != "" {
+		if _, err := os.Stat(*checkpointPath); err == nil {
+			fmt.Fprintf(os.Stderr,
+				"%s already exists: use --resume to continue its simulation "+
+					"or --overwrite to start a new one\n",
+				*checkpointPath,
+			)
+			return exitUsage
+		}
+	}
+
+	if *resume && *overwrite {
+		fmt.Fprintln(os.Stderr, "--resume and --overwrite are mutually exclusive")
+		return exitUsage
+	}
+
+	// the files are written regularly: fail before any job rather than
+	// losing the statistics of the simulation
+	for _, path := range []string{*statisticsPath, *checkpointPath} {
+		if err := checkWritable(path); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitUsage
+		}
+	}
+
+	var simulationsCount uint
+
+	recorder := helpers.GetStatisticsRecorder()
+	recorder.SetNullModel(*generator.nullModel)
+	recorder.SetAlphabet(alphabet)
+	recorder.SetCiphertextLength(len(k4))
+	recorder.SetSeparatorSets(separatorSets)
+	if seed != nil {
+		recorder.SetSeed(*seed)
+	}
+
+	if *metricsPath != "" {
+		loaded, err := helpers.LoadMetrics(*metricsPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitUsage
+		}
+		metrics = append(loaded, metrics...)
+	}
+
+	if err := recorder.SetMetrics(metrics); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	// resume the simulation: its settings prevail
+	if *resume {
+		checkpoint, err := helpers.LoadCheckpoint(*checkpointPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+
+		if err := checkResumedSettings(
+			checkpoint.Settings,
+			recorder.GetSettings(),
+			setFlags,
+		); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitUsage
+		}
+
+		if err := recorder.Restore(checkpoint); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		simulationsCount = checkpoint.SimulationsCount
+
+		seed = checkpoint.Settings.Seed
+		*generator.nullModel = checkpoint.Settings.NullModel
+
+		alphabet, err = alphabets.Parse(checkpoint.Settings.AlphabetName())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+
+		separatorSets = make([][]rune, len(checkpoint.Settings.Separators))
+		for i, separators := range checkpoint.Settings.Separators {
+			separatorSets[i] = []rune(separators)
+		}
+	}
+
+	nullModel, err := generator.getNullModel(alphabet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	// the empirical p-value of the observation made on K4
+	// is only reported for the metrics K4 meets
+	if err := observeK4(recorder, alphabet, separatorSets, *analysis.workers); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitFailure
+	}
+
+	recorder.SetTargetIntervalWidth(*targetIntervalWidth)
+	recorder.SetStatisticsFile(*statisticsPath)
+	recorder.SetCheckpointFile(*checkpointPath)
+
+	// a pseudo-K4 is only counted once all its jobs are completed
+	recorder.SetJobsPerSimulation(uint(len(separatorSets)))
+
+	p, err := newPipeline(analysis, alphabet, separatorSets, recorder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+	p.seed = seed
+	p.quiet = *quiet
+	p.options.NotifyCompletions = true
+
+	if *progressInterval < 0 {
+		fmt.Fprintln(os.Stderr, "invalid progress interval: must be positive")
+		return exitUsage
+	}
+
+	if *progressInterval > 0 || *metricsAddr != "" {
+		p.monitor = newMonitor(recorder, *maxSimulations)
+	}
+
+	if *progressInterval > 0 {
+		p.status = newStatusDisplay(*progressInterval, p.monitor)
+	}
+
+	if *metricsAddr != "" {
+		server, err := serveMetrics(*metricsAddr, p.monitor)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitFailure
+		}
+		defer server.Close()
+	}
+
+	return p.run(func(ctx context.Context, jobs chan<- analyzer.Job) {
+		for {
+			// the target number of pseudo-K4s is reached
+			if *maxSimulations > 0 && simulationsCount >= *maxSimulations {
+				return
+			}
+
+			// generate a random pseudo-K4
+			simulationsCount++
+			ciphertext := nullModel.Generate(
+				getRandomSource(seed, simulationsCount),
+				len(k4),
+			)
+
+			if !sendJobs(ctx, jobs, analyzer.GetJobs(
+				ciphertext,
+				simulationsCount,
+				separatorSets,
+			)) {
+				return
+			}
+
+			// if the statistics are precise enough:
+			// exit gracefully
+			if recorder.TargetReached() {
+				return
+			}
+		}
+	})
+}
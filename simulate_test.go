@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	directory := t.TempDir()
+
+	// a file created for the check is removed
+	missing := filepath.Join(directory, "stats.txt")
+	if err := checkWritable(missing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got: %v", missing, err)
+	}
+
+	// an existing file is kept as is
+	existing := filepath.Join(directory, "checkpoint.json")
+	if err := os.WriteFile(existing, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkWritable(existing); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(existing); err != nil || string(content) != "{}" {
+		t.Errorf("expected the file to be kept, got: %q (%v)", content, err)
+	}
+
+	// a file in a missing directory cannot be written
+	if err := checkWritable(filepath.Join(directory, "missing", "stats.txt")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSimulateExistingCheckpoint(t *testing.T) {
+	directory := t.TempDir()
+	checkpoint := filepath.Join(directory, "checkpoint.json")
+	if err := os.WriteFile(checkpoint, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved simulation is neither resumed nor explicitly overwritten
+	code := runSimulate([]string{
+		"--checkpoint", checkpoint,
+		"--stats", filepath.Join(directory, "stats.txt"),
+		"--simulations", "1",
+	})
+	if code != exitUsage {
+		t.Errorf("expected: %d, got: %d", exitUsage, code)
+	}
+
+	if content, err := os.ReadFile(checkpoint); err != nil || string(content) != "{}" {
+		t.Errorf("expected the checkpoint to be kept, got: %q (%v)", content, err)
+	}
+}